@@ -1,37 +1,174 @@
 package form3
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
 )
 
+// AccountsAPI is the interface implemented by AccountsService. It allows to mock the
+// account resource without faking the whole client. Mocks of Iterate can build the
+// returned iterator with NewAccountIterator.
+type AccountsAPI interface {
+	Create(ctx context.Context, organisationID string, attributes *AccountAttributes, options ...CreateAccountOption) (*Account, error)
+	Fetch(ctx context.Context, id string) (*Account, error)
+	Update(ctx context.Context, id string, version int64, patch *AccountAttributesPatch) (*Account, error)
+	Delete(ctx context.Context, id string, version int64) error
+	DeleteCurrent(ctx context.Context, id string, options ...MutateOption) error
+	List(ctx context.Context, opts *ListAccountsOptions) (*AccountListJSON, error)
+	Mutate(ctx context.Context, id string, mutate func(*Account) error, options ...MutateOption) (*Account, error)
+	Iterate(ctx context.Context, opts *ListAccountsOptions) *AccountIterator
+}
+
+// AccountsService handles communication with the account resource of the Form3 REST API.
+type AccountsService service
+
+var _ AccountsAPI = (*AccountsService)(nil)
+
 // Account represents an account in the form3 org section.
 type Account struct {
-	Attributes     *AccountAttributes `json:"attributes,omitempty"`
-	ID             string             `json:"id,omitempty"`
-	OrganisationID string             `json:"organisation_id,omitempty"`
-	Type           string             `json:"type,omitempty"`
-	Version        *int64             `json:"version,omitempty"`
+	Attributes     *AccountAttributes    `json:"attributes,omitempty"`
+	CreatedOn      *time.Time            `json:"created_on,omitempty"`
+	ID             string                `json:"id,omitempty"`
+	ModifiedOn     *time.Time            `json:"modified_on,omitempty"`
+	OrganisationID string                `json:"organisation_id,omitempty"`
+	Relationships  *AccountRelationships `json:"relationships,omitempty"`
+	Type           string                `json:"type,omitempty"`
+	Version        *int64                `json:"version,omitempty"`
+
+	// Extra holds fields returned by the API that are not modelled by Account.
+	// They are sent back to the API when the account is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // AccountAttributes represents attributes of a single account.
 type AccountAttributes struct {
-	AccountClassification   *string  `json:"account_classification,omitempty"`
-	AccountMatchingOptOut   *bool    `json:"account_matching_opt_out,omitempty"`
-	AccountNumber           string   `json:"account_number,omitempty"`
-	AlternativeNames        []string `json:"alternative_names,omitempty"`
-	BankID                  string   `json:"bank_id,omitempty"`
-	BankIDCode              string   `json:"bank_id_code,omitempty"`
-	BaseCurrency            string   `json:"base_currency,omitempty"`
-	Bic                     string   `json:"bic,omitempty"`
-	Country                 *string  `json:"country,omitempty"`
-	Iban                    string   `json:"iban,omitempty"`
-	JointAccount            *bool    `json:"joint_account,omitempty"`
-	Name                    []string `json:"name,omitempty"`
-	SecondaryIdentification string   `json:"secondary_identification,omitempty"`
-	Status                  *string  `json:"status,omitempty"`
-	Switched                *bool    `json:"switched,omitempty"`
+	AcceptanceQualifier        string                      `json:"acceptance_qualifier,omitempty"`
+	AccountClassification      *string                     `json:"account_classification,omitempty"`
+	AccountMatchingOptOut      *bool                       `json:"account_matching_opt_out,omitempty"`
+	AccountNumber              string                      `json:"account_number,omitempty"`
+	AlternativeNames           []string                    `json:"alternative_names,omitempty"`
+	BankID                     string                      `json:"bank_id,omitempty"`
+	BankIDCode                 string                      `json:"bank_id_code,omitempty"`
+	BaseCurrency               string                      `json:"base_currency,omitempty"`
+	Bic                        string                      `json:"bic,omitempty"`
+	Country                    *string                     `json:"country,omitempty"`
+	Iban                       string                      `json:"iban,omitempty"`
+	JointAccount               *bool                       `json:"joint_account,omitempty"`
+	Name                       []string                    `json:"name,omitempty"`
+	NameMatchingStatus         string                      `json:"name_matching_status,omitempty"`
+	OrganisationIdentification *OrganisationIdentification `json:"organisation_identification,omitempty"`
+	PrivateIdentification      *PrivateIdentification      `json:"private_identification,omitempty"`
+	ProcessingService          string                      `json:"processing_service,omitempty"`
+	ReferenceMask              string                      `json:"reference_mask,omitempty"`
+	SecondaryIdentification    string                      `json:"secondary_identification,omitempty"`
+	Status                     *string                     `json:"status,omitempty"`
+	StatusReason               string                      `json:"status_reason,omitempty"`
+	Switched                   *bool                       `json:"switched,omitempty"`
+	UserDefinedData            []UserDefinedData           `json:"user_defined_data,omitempty"`
+	ValidationType             string                      `json:"validation_type,omitempty"`
+
+	// Extra holds attributes returned by the API that are not modelled by
+	// AccountAttributes. They are sent back to the API when the attributes are marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// PrivateIdentification represents identification of an account held by a private person.
+type PrivateIdentification struct {
+	Address        []string `json:"address,omitempty"`
+	BirthCountry   string   `json:"birth_country,omitempty"`
+	BirthDate      string   `json:"birth_date,omitempty"`
+	City           string   `json:"city,omitempty"`
+	Country        string   `json:"country,omitempty"`
+	Identification string   `json:"identification,omitempty"`
+}
+
+// OrganisationIdentification represents identification of an account held by an organisation.
+type OrganisationIdentification struct {
+	Actors         []OrganisationActor `json:"actors,omitempty"`
+	Address        []string            `json:"address,omitempty"`
+	City           string              `json:"city,omitempty"`
+	Country        string              `json:"country,omitempty"`
+	Identification string              `json:"identification,omitempty"`
+	Name           []string            `json:"name,omitempty"`
+	Registration   string              `json:"registration_number,omitempty"`
+	TaxResidency   string              `json:"tax_residency,omitempty"`
+}
+
+// OrganisationActor represents a person acting on behalf of the organisation.
+type OrganisationActor struct {
+	BirthDate string   `json:"birth_date,omitempty"`
+	Name      []string `json:"name,omitempty"`
+	Residency string   `json:"residency,omitempty"`
+}
+
+// UserDefinedData represents a custom key-value pair stored with the account.
+type UserDefinedData struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// AccountRelationships represents resources related to the account.
+type AccountRelationships struct {
+	AccountEvents *RelationshipData `json:"account_events,omitempty"`
+	MasterAccount *RelationshipData `json:"master_account,omitempty"`
+}
+
+// RelationshipData represents a list of related resources.
+type RelationshipData struct {
+	Data []ResourceIdentifier `json:"data"`
+}
+
+// ResourceIdentifier identifies a single resource.
+type ResourceIdentifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// CreateAccountOption represents an option that can be used to configure the account
+// being created.
+type CreateAccountOption func(*Account)
+
+// WithRelationships allows to set relationships of the account being created, e.g. the
+// master account of a sub-account.
+func WithRelationships(relationships *AccountRelationships) CreateAccountOption {
+	return func(a *Account) {
+		a.Relationships = relationships
+	}
+}
+
+// WithMasterAccount allows to create a sub-account of the account with the given identifier.
+func WithMasterAccount(id string) CreateAccountOption {
+	return func(a *Account) {
+		if a.Relationships == nil {
+			a.Relationships = &AccountRelationships{}
+		}
+		a.Relationships.MasterAccount = &RelationshipData{
+			Data: []ResourceIdentifier{{ID: id, Type: "accounts"}},
+		}
+	}
+}
+
+// WithAccountID allows to set the identifier of the account being created instead of
+// generating a new one. Reusing the same identifier makes the creation idempotent.
+func WithAccountID(id string) CreateAccountOption {
+	return func(a *Account) {
+		a.ID = id
+	}
+}
+
+// NewDeterministicID returns an account identifier derived from the organisation
+// identifier and the given key, e.g. a customer reference. The same input always
+// results in the same identifier.
+func NewDeterministicID(organisationID, key string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(organisationID+"/"+key)).String()
 }
 
 // AccountJSON represents request payload to account resource.
@@ -39,60 +176,278 @@ type AccountJSON struct {
 	Data Account `json:"data,omitempty"`
 }
 
-// FetchAccount returns account with the given identifier.
-func (c *Client) FetchAccount(id string) (*Account, error) {
+// AccountListJSON represents response payload of the account list resource.
+type AccountListJSON struct {
+	Data  []Account `json:"data"`
+	Links *Links    `json:"links,omitempty"`
+}
+
+// Links represents JSON:API pagination links.
+type Links struct {
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Self  string `json:"self,omitempty"`
+}
+
+// AccountFilter represents filters that can be applied when listing accounts.
+// Empty fields are ignored.
+type AccountFilter struct {
+	AccountNumber string
+	BankID        string
+	BankIDCode    string
+	Country       string
+	CustomerID    string
+	Iban          string
+}
+
+// ListAccountsOptions represents pagination and filtering options of ListAccounts.
+type ListAccountsOptions struct {
+	PageNumber int
+	PageSize   int
+	Filter     AccountFilter
+}
+
+// values returns options encoded as URL query parameters.
+func (o *ListAccountsOptions) values() url.Values {
+	values := url.Values{}
+	if o == nil {
+		return values
+	}
+	if o.PageNumber > 0 {
+		values.Set("page[number]", strconv.Itoa(o.PageNumber))
+	}
+	if o.PageSize > 0 {
+		values.Set("page[size]", strconv.Itoa(o.PageSize))
+	}
+	filters := map[string]string{
+		"filter[account_number]": o.Filter.AccountNumber,
+		"filter[bank_id]":        o.Filter.BankID,
+		"filter[bank_id_code]":   o.Filter.BankIDCode,
+		"filter[country]":        o.Filter.Country,
+		"filter[customer_id]":    o.Filter.CustomerID,
+		"filter[iban]":           o.Filter.Iban,
+	}
+	for key, value := range filters {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values
+}
+
+// List returns a single page of accounts matching the given options.
+// Pagination links returned by the API can be used to fetch subsequent pages.
+func (s *AccountsService) List(ctx context.Context, opts *ListAccountsOptions) (*AccountListJSON, error) {
+	endpoint := "/v1/organisation/accounts"
+	if query := opts.values().Encode(); query != "" {
+		endpoint += "?" + query
+	}
+	return s.list(ctx, endpoint)
+}
+
+// list returns a single page of accounts from the given endpoint.
+func (s *AccountsService) list(ctx context.Context, endpoint string) (*AccountListJSON, error) {
+	headers := map[string]string{"Accept": "application/vnd.api+json"}
+
+	request, err := s.client.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	accountListJSON := new(AccountListJSON)
+	if err = s.client.request(OperationListAccounts, accountListJSON, request, headers); err != nil {
+		return nil, err
+	}
+	return accountListJSON, nil
+}
+
+// Fetch returns account with the given identifier.
+func (s *AccountsService) Fetch(ctx context.Context, id string) (*Account, error) {
 	endpoint := fmt.Sprintf("/v1/organisation/accounts/%s", id)
 	headers := map[string]string{"Accept": "application/vnd.api+json"}
 
-	request, err := c.NewRequest(http.MethodGet, endpoint, nil)
+	request, err := s.client.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	accountJSON := new(AccountJSON)
-	if err = c.Request(accountJSON, request, headers); err != nil {
+	if err = s.client.request(OperationFetchAccount, accountJSON, request, headers); err != nil {
 		return nil, err
 	}
 	return &accountJSON.Data, nil
 }
 
-// CreateAccount creates account with the given attributes.
-func (c *Client) CreateAccount(organisationId string, attributes *AccountAttributes) (*Account, error) {
+// Create creates account with the given attributes.
+//
+// Account identifier is generated by the client's ID generator unless it is set with
+// WithAccountID. Creation is idempotent: when an account with the same identifier
+// already exists (409 Conflict) and it belongs to the same organisation and has the
+// attributes that were sent, the existing account is returned instead of an error. Therefore,
+// creation requests are retried according to the client's RetryPolicy.
+//
+// If the client is configured WithValidation, the attributes are validated first
+// and *ValidationError is returned without sending the request.
+func (s *AccountsService) Create(ctx context.Context, organisationID string, attributes *AccountAttributes, options ...CreateAccountOption) (*Account, error) {
 	endpoint := "/v1/organisation/accounts"
-	headers := map[string]string{
-		"Accept":       "application/vnd.api+json",
-		"Content-Type": "application/vnd.api+json",
+	if s.client.validate {
+		if err := attributes.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
 	payload := AccountJSON{
 		Data: Account{
 			Attributes:     attributes,
-			ID:             uuid.NewString(),
-			OrganisationID: organisationId,
+			ID:             s.client.idGenerator(),
+			OrganisationID: organisationID,
 			Type:           "accounts",
 		},
 	}
-	request, err := c.NewRequest(http.MethodPost, endpoint, payload)
+	for _, option := range options {
+		option(&payload.Data)
+	}
+	headers := map[string]string{
+		"Accept":             "application/vnd.api+json",
+		"Content-Type":       "application/vnd.api+json",
+		IdempotencyKeyHeader: payload.Data.ID,
+	}
+	request, err := s.client.NewRequestWithContext(ctx, http.MethodPost, endpoint, payload)
 	if err != nil {
 		return nil, err
 	}
 
 	accountJSON := new(AccountJSON)
-	if err = c.Request(accountJSON, request, headers); err != nil {
+	if err = s.client.request(OperationCreateAccount, accountJSON, request, headers); err != nil {
+		if errors.Is(err, ErrConflict) {
+			if existing, ok := s.existing(ctx, &payload.Data); ok {
+				return existing, nil
+			}
+		}
 		return nil, err
 	}
 	return &accountJSON.Data, nil
 }
 
-// DeleteAccount deletes the account with the given identifier.
-func (c *Client) DeleteAccount(id string, version int64) error {
+// existing returns the already created account with the identifier of the given
+// account if it matches the organisation and attributes of the given account. Only
+// the attributes sent are compared, as the API fills in the others, e.g. the IBAN.
+func (s *AccountsService) existing(ctx context.Context, account *Account) (*Account, bool) {
+	existing, err := s.Fetch(ctx, account.ID)
+	if err != nil || existing.OrganisationID != account.OrganisationID {
+		return nil, false
+	}
+	sent, err := attributeFields(account.Attributes)
+	if err != nil {
+		return nil, false
+	}
+	stored, err := attributeFields(existing.Attributes)
+	if err != nil {
+		return nil, false
+	}
+	for name, value := range sent {
+		if !equalJSON(stored[name], value) {
+			return nil, false
+		}
+	}
+	return existing, true
+}
+
+// equalJSON reports whether a and b have the same JSON encoding regardless of the
+// order of the fields.
+func equalJSON(a, b interface{}) bool {
+	var decodedA, decodedB interface{}
+	for _, v := range []struct {
+		value   interface{}
+		decoded *interface{}
+	}{{a, &decodedA}, {b, &decodedB}} {
+		data, err := json.Marshal(v.value)
+		if err != nil {
+			return false
+		}
+		if err = json.Unmarshal(data, v.decoded); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(decodedA, decodedB)
+}
+
+// Update modifies attributes of the account with the given identifier. The version
+// must match the current version of the account, otherwise the API responds with
+// 409 Conflict.
+func (s *AccountsService) Update(ctx context.Context, id string, version int64, patch *AccountAttributesPatch) (*Account, error) {
+	return s.update(ctx, id, version, patch)
+}
+
+// update sends the given attributes modification of the account.
+func (s *AccountsService) update(ctx context.Context, id string, version int64, attributes interface{}) (*Account, error) {
+	endpoint := fmt.Sprintf("/v1/organisation/accounts/%s", id)
+	headers := map[string]string{
+		"Accept":       "application/vnd.api+json",
+		"Content-Type": "application/vnd.api+json",
+	}
+
+	payload := accountPatchJSON{
+		Data: accountPatch{
+			Attributes: attributes,
+			ID:         id,
+			Type:       "accounts",
+			Version:    version,
+		},
+	}
+	request, err := s.client.NewRequestWithContext(ctx, http.MethodPatch, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	accountJSON := new(AccountJSON)
+	if err = s.client.request(OperationUpdateAccount, accountJSON, request, headers); err != nil {
+		return nil, err
+	}
+	return &accountJSON.Data, nil
+}
+
+// Delete deletes the account with the given identifier and version.
+func (s *AccountsService) Delete(ctx context.Context, id string, version int64) error {
 	endpoint := fmt.Sprintf("/v1/organisation/accounts/%s?version=%d", id, version)
 	headers := map[string]string{"Accept": "application/vnd.api+json"}
 
-	request, err := c.NewRequest(http.MethodDelete, endpoint, nil)
+	request, err := s.client.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
 	if err != nil {
 		return err
 	}
 
-	return c.Request(nil, request, headers)
+	return s.client.request(OperationDeleteAccount, nil, request, headers)
+}
+
+// FetchAccount returns account with the given identifier. The request is bounded by
+// DefaultTimeout.
+//
+// Deprecated: Use Client.Accounts.Fetch instead.
+func (c *Client) FetchAccount(id string) (*Account, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.Accounts.Fetch(ctx, id)
+}
+
+// CreateAccount creates account with the given attributes. The request is bounded by
+// DefaultTimeout.
+//
+// Deprecated: Use Client.Accounts.Create instead.
+func (c *Client) CreateAccount(organisationId string, attributes *AccountAttributes) (*Account, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.Accounts.Create(ctx, organisationId, attributes)
+}
+
+// DeleteAccount deletes the account with the given identifier. The request is bounded
+// by DefaultTimeout.
+//
+// Deprecated: Use Client.Accounts.Delete instead.
+func (c *Client) DeleteAccount(id string, version int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.Accounts.Delete(ctx, id, version)
 }
@@ -1,14 +1,21 @@
-//go:build integration
-// +build integration
-
 package form3_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-test/deep"
 	"github.com/google/uuid"
 	"github.com/lmikolajczak/go-form3/form3"
+	"github.com/lmikolajczak/go-form3/form3/form3test"
+	"github.com/lmikolajczak/go-form3/form3/iban"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 )
 
 func accountAttributesRequired(t *testing.T) *form3.AccountAttributes {
@@ -31,7 +38,7 @@ func accountAttributesOptional(t *testing.T) *form3.AccountAttributes {
 	attributes.BankIDCode = "ABNANL"
 	attributes.BaseCurrency = "EUR"
 	attributes.Bic = "ABNANL2A"
-	attributes.Iban = "NL91ABNA0417164300"
+	attributes.Iban = testIban(t, "NL", attributes.BankID, attributes.AccountNumber)
 	attributes.JointAccount = form3.Bool(false)
 	attributes.SecondaryIdentification = "Secondary Identification"
 	attributes.Status = form3.String("pending")
@@ -50,7 +57,7 @@ func accountAttributesInvalid(t *testing.T) *form3.AccountAttributes {
 }
 
 func TestClient_CreateAccount(t *testing.T) {
-	f3, teardown := form3.TestClient(t)
+	f3, teardown := form3test.TestClient(t)
 	defer teardown()
 
 	testcases := []struct {
@@ -105,8 +112,278 @@ func TestClient_CreateAccount(t *testing.T) {
 	}
 }
 
+func TestAccountsService_Create(t *testing.T) {
+	f3, teardown := form3test.TestClient(t)
+	defer teardown()
+
+	testcases := []struct {
+		name       string
+		attributes *form3.AccountAttributes
+		wantAttrs  *form3.AccountAttributes
+		wantErr    error
+	}{
+		{
+			name:       "required attributes only",
+			attributes: accountAttributesRequired(t),
+			wantAttrs: &form3.AccountAttributes{
+				Country: form3.String("NL"),
+				Name:    []string{"L. Mikolajczak"},
+			},
+		},
+		{
+			name:       "with optional attributes",
+			attributes: accountAttributesOptional(t),
+			wantAttrs:  accountAttributesOptional(t),
+		},
+		{
+			name: "without attributes",
+			wantErr: &form3.F3Error{
+				StatusCode:   400,
+				ErrorCode:    0,
+				ErrorMessage: "validation failure list:\nvalidation failure list:\nattributes in body is required",
+			},
+		},
+		{
+			name:       "parse validation errors",
+			attributes: accountAttributesInvalid(t),
+			wantErr: &form3.F3Error{
+				StatusCode:   400,
+				ErrorCode:    0,
+				ErrorMessage: "validation failure list:\nvalidation failure list:\nvalidation failure list:\naccount_number in body should match '^[A-Z0-9]{0,64}$'",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			organisationID := uuid.NewString()
+			account, err := f3.Accounts.Create(context.Background(), organisationID, tc.attributes)
+			if err != nil {
+				testErrorMessage(t, err, tc.wantErr)
+			} else {
+				testUUID(t, account.OrganisationID, organisationID)
+				testAccountAttrs(t, account.Attributes, tc.wantAttrs)
+			}
+		})
+	}
+}
+
+func TestAccountsService_CreateRelationships(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	masterID, eventID := uuid.NewString(), uuid.NewString()
+	testcases := []struct {
+		name    string
+		options []form3.CreateAccountOption
+		want    string
+	}{
+		{
+			name:    "master account",
+			options: []form3.CreateAccountOption{form3.WithMasterAccount(masterID)},
+			want:    `{"master_account":{"data":[{"id":"` + masterID + `","type":"accounts"}]}}`,
+		},
+		{
+			name: "relationships",
+			options: []form3.CreateAccountOption{form3.WithRelationships(&form3.AccountRelationships{
+				AccountEvents: &form3.RelationshipData{Data: []form3.ResourceIdentifier{{ID: eventID, Type: "account_events"}}},
+			})},
+			want: `{"account_events":{"data":[{"id":"` + eventID + `","type":"account_events"}]}}`,
+		},
+	}
+
+	var relationships []json.RawMessage
+	server.AddHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			var payload struct {
+				Data struct {
+					Relationships json.RawMessage `json:"relationships"`
+				} `json:"data"`
+			}
+			json.Unmarshal(body, &payload)
+			relationships = append(relationships, payload.Data.Relationships)
+		}
+		return false
+	})
+
+	for i, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			account, err := f3.Accounts.Create(context.Background(), uuid.NewString(), accountAttributesRequired(t), tc.options...)
+			if err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+			if len(relationships) != i+1 || string(relationships[i]) != tc.want {
+				t.Errorf("sent relationships = %s; want: %s", relationships, tc.want)
+			}
+			got, err := json.Marshal(account.Relationships)
+			if err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("relationships = %s; want: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAccount_JSON(t *testing.T) {
+	data := `{"attributes":{"country":"GB","name":["Acme Ltd"],` +
+		`"organisation_identification":{"actors":[{"birth_date":"1980-01-02","name":["J. Smith"],"residency":"GB"}],"address":["1 High Street"],"city":"London","country":"GB","identification":"123654","name":["Acme"],"registration_number":"0123456","tax_residency":"GB"},` +
+		`"private_identification":{"address":["2 Low Street"],"birth_country":"GB","birth_date":"1980-01-02","city":"Leeds","country":"GB","identification":"13YH458762"},` +
+		`"user_defined_data":[{"key":"customer","value":"42"}]},` +
+		`"created_on":"2021-01-02T03:04:05.678Z","id":"ad27e265",` +
+		`"modified_on":"2021-02-03T04:05:06Z","organisation_id":"eb0bd6f5",` +
+		`"relationships":{"master_account":{"data":[{"id":"a52d13a4","type":"accounts"}]}},"type":"accounts","version":1}`
+
+	account := new(form3.Account)
+	if err := json.Unmarshal([]byte(data), account); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+
+	version := int64(1)
+	want := &form3.Account{
+		Attributes: &form3.AccountAttributes{
+			Country: form3.String("GB"),
+			Name:    []string{"Acme Ltd"},
+			OrganisationIdentification: &form3.OrganisationIdentification{
+				Actors:         []form3.OrganisationActor{{BirthDate: "1980-01-02", Name: []string{"J. Smith"}, Residency: "GB"}},
+				Address:        []string{"1 High Street"},
+				City:           "London",
+				Country:        "GB",
+				Identification: "123654",
+				Name:           []string{"Acme"},
+				Registration:   "0123456",
+				TaxResidency:   "GB",
+			},
+			PrivateIdentification: &form3.PrivateIdentification{
+				Address:        []string{"2 Low Street"},
+				BirthCountry:   "GB",
+				BirthDate:      "1980-01-02",
+				City:           "Leeds",
+				Country:        "GB",
+				Identification: "13YH458762",
+			},
+			UserDefinedData: []form3.UserDefinedData{{Key: "customer", Value: "42"}},
+		},
+		CreatedOn:      testTime(time.Date(2021, 1, 2, 3, 4, 5, 678000000, time.UTC)),
+		ID:             "ad27e265",
+		ModifiedOn:     testTime(time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)),
+		OrganisationID: "eb0bd6f5",
+		Relationships: &form3.AccountRelationships{
+			MasterAccount: &form3.RelationshipData{Data: []form3.ResourceIdentifier{{ID: "a52d13a4", Type: "accounts"}}},
+		},
+		Type:    "accounts",
+		Version: &version,
+	}
+	if diff := deep.Equal(account, want); diff != nil {
+		t.Error(diff)
+	}
+
+	got, err := json.Marshal(account)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if string(got) != data {
+		t.Errorf("json = %s; want: %s", got, data)
+	}
+}
+
+func TestAccountsService_CreateIdempotent(t *testing.T) {
+	f3, teardown := form3test.TestClient(t)
+	defer teardown()
+
+	organisationID := uuid.NewString()
+	accountID := form3.NewDeterministicID(organisationID, uuid.NewString())
+	created, err := f3.Accounts.Create(context.Background(), organisationID, accountAttributesRequired(t), form3.WithAccountID(accountID))
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	testUUID(t, created.ID, accountID)
+
+	testcases := []struct {
+		name           string
+		organisationID string
+		attributes     *form3.AccountAttributes
+		wantErr        error
+	}{
+		{
+			name:           "same attributes",
+			organisationID: organisationID,
+			attributes:     accountAttributesRequired(t),
+		},
+		{
+			name:           "different attributes",
+			organisationID: organisationID,
+			attributes:     accountAttributesOptional(t),
+			wantErr: &form3.F3Error{
+				StatusCode:   409,
+				ErrorCode:    0,
+				ErrorMessage: "Account cannot be created as it violates a duplicate constraint",
+			},
+		},
+		{
+			name:           "different organisation",
+			organisationID: uuid.NewString(),
+			attributes:     accountAttributesRequired(t),
+			wantErr: &form3.F3Error{
+				StatusCode:   409,
+				ErrorCode:    0,
+				ErrorMessage: "Account cannot be created as it violates a duplicate constraint",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			account, err := f3.Accounts.Create(context.Background(), tc.organisationID, tc.attributes, form3.WithAccountID(accountID))
+			testErrorMessage(t, err, tc.wantErr)
+			if err == nil {
+				testUUID(t, account.ID, accountID)
+				testAccountAttrs(t, account.Attributes, tc.attributes)
+			}
+		})
+	}
+}
+
+func TestAccountsService_CreateIdempotentServerDefaults(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	organisationID := uuid.NewString()
+	accountID := uuid.NewString()
+	if _, err := f3.Accounts.Create(context.Background(), organisationID, accountAttributesRequired(t), form3.WithAccountID(accountID)); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+
+	// The API fills in the attributes that were not sent.
+	stored, _ := server.Account(accountID)
+	stored.Attributes.Iban = "NL91ABNA0417164300"
+	stored.Attributes.Status = form3.String("confirmed")
+	server.PutAccount(*stored)
+
+	account, err := f3.Accounts.Create(context.Background(), organisationID, accountAttributesRequired(t), form3.WithAccountID(accountID))
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if got, want := account.Attributes.Iban, stored.Attributes.Iban; got != want {
+		t.Errorf("iban = %s; want: %s", got, want)
+	}
+
+	// An attribute that was sent must still match.
+	attributes := accountAttributesRequired(t)
+	attributes.Status = form3.String("pending")
+	_, err = f3.Accounts.Create(context.Background(), organisationID, attributes, form3.WithAccountID(accountID))
+	if !errors.Is(err, form3.ErrConflict) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrConflict)
+	}
+}
+
 func TestClient_DeleteAccount(t *testing.T) {
-	f3, teardown := form3.TestClient(t)
+	f3, teardown := form3test.TestClient(t)
 	defer teardown()
 
 	account, _ := f3.CreateAccount(uuid.NewString(), accountAttributesRequired(t))
@@ -154,8 +431,57 @@ func TestClient_DeleteAccount(t *testing.T) {
 	}
 }
 
+func TestAccountsService_Delete(t *testing.T) {
+	f3, teardown := form3test.TestClient(t)
+	defer teardown()
+
+	account, _ := f3.Accounts.Create(context.Background(), uuid.NewString(), accountAttributesRequired(t))
+
+	testcases := []struct {
+		name           string
+		accountID      string
+		accountVersion int64
+		wantErr        error
+	}{
+		{
+			name:           "account does not exist",
+			accountID:      uuid.NewString(),
+			accountVersion: 0,
+			wantErr: &form3.F3Error{
+				StatusCode:   404,
+				ErrorCode:    0,
+				ErrorMessage: "",
+			},
+		},
+		{
+			name:           "invalid version for existing account",
+			accountID:      account.ID,
+			accountVersion: 123,
+			wantErr: &form3.F3Error{
+				StatusCode:   409,
+				ErrorCode:    0,
+				ErrorMessage: "invalid version",
+			},
+		},
+		{
+			name:           "success",
+			accountID:      account.ID,
+			accountVersion: 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := f3.Accounts.Delete(context.Background(), tc.accountID, tc.accountVersion)
+			if err != nil {
+				testErrorMessage(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestClient_FetchAccount(t *testing.T) {
-	f3, teardown := form3.TestClient(t)
+	f3, teardown := form3test.TestClient(t)
 	defer teardown()
 
 	acc, _ := f3.CreateAccount(uuid.NewString(), accountAttributesRequired(t))
@@ -199,6 +525,125 @@ func TestClient_FetchAccount(t *testing.T) {
 	}
 }
 
+func TestAccountsService_Fetch(t *testing.T) {
+	f3, teardown := form3test.TestClient(t)
+	defer teardown()
+
+	acc, _ := f3.Accounts.Create(context.Background(), uuid.NewString(), accountAttributesRequired(t))
+	nonExistingAccountId := uuid.NewString()
+
+	testcases := []struct {
+		name      string
+		accountID string
+		wantAttrs *form3.AccountAttributes
+		wantErr   error
+	}{
+		{
+			name:      "account does not exist",
+			accountID: nonExistingAccountId,
+			wantErr: &form3.F3Error{
+				StatusCode:   404,
+				ErrorCode:    0,
+				ErrorMessage: fmt.Sprintf("record %s does not exist", nonExistingAccountId),
+			},
+		},
+		{
+			name:      "success",
+			accountID: acc.ID,
+			wantAttrs: &form3.AccountAttributes{
+				Country: form3.String("NL"),
+				Name:    []string{"L. Mikolajczak"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			account, err := f3.Accounts.Fetch(context.Background(), tc.accountID)
+			if err != nil {
+				testErrorMessage(t, err, tc.wantErr)
+			} else {
+				testUUID(t, account.ID, tc.accountID)
+				testAccountAttrs(t, account.Attributes, tc.wantAttrs)
+			}
+		})
+	}
+}
+
+func TestAccountsService_List(t *testing.T) {
+	f3, teardown := form3test.TestClient(t)
+	defer teardown()
+
+	attributes := accountAttributesRequired(t)
+	attributes.AccountNumber = strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", ""))
+	for i := 0; i < 3; i++ {
+		if _, err := f3.Accounts.Create(context.Background(), uuid.NewString(), attributes); err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+	}
+
+	testcases := []struct {
+		name      string
+		opts      *form3.ListAccountsOptions
+		wantCount int
+		wantNext  bool
+	}{
+		{
+			name: "filter by account number",
+			opts: &form3.ListAccountsOptions{
+				Filter: form3.AccountFilter{AccountNumber: attributes.AccountNumber},
+			},
+			wantCount: 3,
+		},
+		{
+			name: "first page",
+			opts: &form3.ListAccountsOptions{
+				PageSize: 2,
+				Filter:   form3.AccountFilter{AccountNumber: attributes.AccountNumber},
+			},
+			wantCount: 2,
+			wantNext:  true,
+		},
+		{
+			name: "last page",
+			opts: &form3.ListAccountsOptions{
+				PageNumber: 1,
+				PageSize:   2,
+				Filter:     form3.AccountFilter{AccountNumber: attributes.AccountNumber},
+			},
+			wantCount: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := f3.Accounts.List(context.Background(), tc.opts)
+			if err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+			if got := len(list.Data); got != tc.wantCount {
+				t.Errorf("accounts = %d; want: %d", got, tc.wantCount)
+			}
+			if got := list.Links != nil && list.Links.Next != ""; got != tc.wantNext {
+				t.Errorf("has next link = %t; want: %t", got, tc.wantNext)
+			}
+		})
+	}
+}
+
+func testIban(t *testing.T, country, bankID, accountNumber string) string {
+	t.Helper()
+	generated, err := iban.New(country, bankID, accountNumber)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	return generated.String()
+}
+
+func testTime(v time.Time) *time.Time {
+	return &v
+}
+
 func testUUID(t *testing.T, uuid string, want string) {
 	t.Helper()
 	if got := uuid; got != want {
@@ -0,0 +1,241 @@
+package form3
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lmikolajczak/go-form3/form3/bic"
+	"github.com/lmikolajczak/go-form3/form3/iban"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError represents validation failures of a request detected by the client
+// before it is sent to the Form3 REST API. It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	FieldErrors []FieldError
+}
+
+// Error returns a string representation of the ValidationError in the format used
+// by the Form3 REST API.
+func (e *ValidationError) Error() string {
+	failures := make([]string, 0, len(e.FieldErrors)+1)
+	failures = append(failures, validationFailureList)
+	for _, fieldError := range e.FieldErrors {
+		failures = append(failures, fieldError.Error())
+	}
+	return strings.Join(failures, "\n")
+}
+
+// Is reports whether the target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// WithValidation makes the client validate account attributes with
+// AccountAttributes.Validate before sending them to the API.
+func WithValidation() ClientOption {
+	return func(c *Client) {
+		c.validate = true
+	}
+}
+
+// Constraints of the account attributes documented by the Form3 REST API.
+var (
+	accountNumberPattern = regexp.MustCompile(`^[A-Z0-9]{0,64}$`)
+	bankIDPattern        = regexp.MustCompile(`^[A-Z0-9]{0,16}$`)
+	bankIDCodePattern    = regexp.MustCompile(`^[A-Z]{0,16}$`)
+	baseCurrencyPattern  = regexp.MustCompile(`^[A-Z]{3}$`)
+	bicPattern           = regexp.MustCompile(`^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$`)
+	countryPattern       = regexp.MustCompile(`^[A-Z]{2}$`)
+	ibanPattern          = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{0,64}$`)
+
+	accountClassifications = []string{"Personal", "Business"}
+	accountStatuses        = []string{"pending", "confirmed", "failed"}
+)
+
+// Limits of the account names. The length is counted in characters, not bytes.
+const (
+	maxNames            = 4
+	maxAlternativeNames = 3
+	maxNameLength       = 140
+)
+
+// countries lists ISO 3166-1 alpha-2 country codes.
+var countries = setOf(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ
+	BR BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM
+	DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS
+	GT GU GW GY HK HM HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN
+	KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ
+	MR MS MT MU MV MW MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM
+	PN PR PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV
+	SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI
+	VN VU WF WS YE YT ZA ZM ZW
+`)
+
+// currencies lists ISO 4217 currency codes.
+var currencies = setOf(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL BSD BTN BWP
+	BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP
+	GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR
+	KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK
+	MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR
+	SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS
+	UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XOF XPF YER ZAR ZMW ZWL
+`)
+
+// setOf returns a set of the whitespace separated values.
+func setOf(values string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, value := range strings.Fields(values) {
+		set[value] = struct{}{}
+	}
+	return set
+}
+
+// Validate checks the attributes against the constraints documented by the Form3
+// REST API, including the rules of the account country (see RegisterCountryRule).
+// It returns *ValidationError with the same field errors the API would report or
+// nil if the attributes are valid.
+func (a *AccountAttributes) Validate() error {
+	return a.validate(true)
+}
+
+// ValidateSchema checks the attributes only against the JSON schema of the Form3
+// REST API. Unlike Validate, it does not check the country and currency codes, the
+// IBAN and BIC of the account or the rules of the account country.
+func (a *AccountAttributes) ValidateSchema() error {
+	return a.validate(false)
+}
+
+// validate checks the attributes against the JSON schema of the API and, if strict,
+// against the rules the API applies on top of it.
+func (a *AccountAttributes) validate(strict bool) error {
+	if a == nil {
+		return &ValidationError{FieldErrors: []FieldError{{Field: "attributes", Rule: RuleRequired}}}
+	}
+
+	var fieldErrors []FieldError
+	switch {
+	case a.Country == nil:
+		fieldErrors = append(fieldErrors, FieldError{Field: "country", Rule: RuleRequired})
+	case !countryPattern.MatchString(*a.Country):
+		fieldErrors = append(fieldErrors, FieldError{Field: "country", Rule: RulePattern, Detail: countryPattern.String()})
+	case strict:
+		if _, ok := countries[*a.Country]; !ok {
+			fieldErrors = append(fieldErrors, FieldError{Field: "country", Rule: RuleInvalid, Detail: "is not a valid ISO 3166-1 country code"})
+		}
+	}
+	fieldErrors = append(fieldErrors, validateNames("name", a.Name, 1, maxNames)...)
+	fieldErrors = append(fieldErrors, validateNames("alternative_names", a.AlternativeNames, 0, maxAlternativeNames)...)
+
+	patterns := []struct {
+		field   string
+		value   string
+		pattern *regexp.Regexp
+	}{
+		{"account_number", a.AccountNumber, accountNumberPattern},
+		{"bank_id", a.BankID, bankIDPattern},
+		{"bank_id_code", a.BankIDCode, bankIDCodePattern},
+		{"base_currency", a.BaseCurrency, baseCurrencyPattern},
+		{"bic", a.Bic, bicPattern},
+		{"iban", a.Iban, ibanPattern},
+	}
+	for _, p := range patterns {
+		if p.value != "" && !p.pattern.MatchString(p.value) {
+			fieldErrors = append(fieldErrors, FieldError{Field: p.field, Rule: RulePattern, Detail: p.pattern.String()})
+		}
+	}
+	if strict {
+		if a.Iban != "" && ibanPattern.MatchString(a.Iban) {
+			fieldErrors = append(fieldErrors, validateIban(a.Iban, a.Country)...)
+		}
+		if a.Bic != "" && bicPattern.MatchString(a.Bic) {
+			fieldErrors = append(fieldErrors, validateBic(a.Bic, a.Country)...)
+		}
+		if a.BaseCurrency != "" && baseCurrencyPattern.MatchString(a.BaseCurrency) {
+			if _, ok := currencies[a.BaseCurrency]; !ok {
+				fieldErrors = append(fieldErrors, FieldError{Field: "base_currency", Rule: RuleInvalid, Detail: "is not a valid ISO 4217 currency code"})
+			}
+		}
+	}
+
+	if c := a.AccountClassification; c != nil && !contains(accountClassifications, *c) {
+		fieldErrors = append(fieldErrors, FieldError{Field: "account_classification", Rule: RuleEnum, Detail: strings.Join(accountClassifications, " ")})
+	}
+	if s := a.Status; s != nil && !contains(accountStatuses, *s) {
+		fieldErrors = append(fieldErrors, FieldError{Field: "status", Rule: RuleEnum, Detail: strings.Join(accountStatuses, " ")})
+	}
+	if utf8.RuneCountInString(a.SecondaryIdentification) > maxNameLength {
+		fieldErrors = append(fieldErrors, FieldError{Field: "secondary_identification", Rule: RuleMaxLength, Detail: strconv.Itoa(maxNameLength)})
+	}
+	if strict {
+		fieldErrors = append(fieldErrors, a.validateCountry()...)
+	}
+
+	if len(fieldErrors) > 0 {
+		return &ValidationError{FieldErrors: fieldErrors}
+	}
+	return nil
+}
+
+// validateIban returns field errors of the IBAN. IBANs of countries with unknown
+// structure are only checked against the pattern.
+func validateIban(value string, country *string) []FieldError {
+	parsed, err := iban.Parse(value)
+	switch {
+	case errors.Is(err, iban.ErrUnsupportedCountry):
+		return nil
+	case err != nil:
+		detail := "is not a valid IBAN: " + strings.TrimPrefix(err.Error(), "iban: ")
+		return []FieldError{{Field: "iban", Rule: RuleInvalid, Detail: detail}}
+	case country != nil && parsed.CountryCode != *country:
+		return []FieldError{{Field: "iban", Rule: RuleInvalid, Detail: "does not match the account country"}}
+	}
+	return nil
+}
+
+// validateBic returns field errors of the BIC.
+func validateBic(value string, country *string) []FieldError {
+	parsed, err := bic.Parse(value)
+	if err != nil {
+		detail := "is not a valid BIC: " + strings.TrimPrefix(err.Error(), "bic: ")
+		return []FieldError{{Field: "bic", Rule: RuleInvalid, Detail: detail}}
+	}
+	if _, ok := countries[parsed.Country]; !ok {
+		return []FieldError{{Field: "bic", Rule: RuleInvalid, Detail: "is not a valid BIC: unknown country " + parsed.Country}}
+	}
+	if country != nil && parsed.Country != *country {
+		return []FieldError{{Field: "bic", Rule: RuleInvalid, Detail: "does not match the account country"}}
+	}
+	return nil
+}
+
+// validateNames returns field errors of the list of names with the given field name.
+func validateNames(field string, names []string, min, max int) []FieldError {
+	var fieldErrors []FieldError
+	switch {
+	case min > 0 && len(names) == 0:
+		fieldErrors = append(fieldErrors, FieldError{Field: field, Rule: RuleRequired})
+	case len(names) > max:
+		fieldErrors = append(fieldErrors, FieldError{Field: field, Rule: RuleMaxItems, Detail: strconv.Itoa(max)})
+	}
+	for i, name := range names {
+		if utf8.RuneCountInString(name) > maxNameLength {
+			fieldErrors = append(fieldErrors, FieldError{Field: fmt.Sprintf("%s.%d", field, i), Rule: RuleMaxLength, Detail: strconv.Itoa(maxNameLength)})
+		}
+	}
+	return fieldErrors
+}
+
+// contains reports whether the value is present in the values.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"github.com/go-test/deep"
+	"github.com/google/uuid"
+	"github.com/lmikolajczak/go-form3/form3"
+	"github.com/lmikolajczak/go-form3/form3/form3test"
+	"strings"
+	"testing"
+)
+
+func TestAccountAttributes_Validate(t *testing.T) {
+	testcases := []struct {
+		name       string
+		attributes *form3.AccountAttributes
+		want       []form3.FieldError
+	}{
+		{
+			name: "valid",
+			attributes: &form3.AccountAttributes{
+				AccountClassification: form3.String("Personal"),
+				AccountNumber:         "41426819",
+				BankID:                "400300",
+				BankIDCode:            "GBDSC",
+				BaseCurrency:          "GBP",
+				Bic:                   "NWBKGB22",
+				Country:               form3.String("GB"),
+				Name:                  []string{"Samantha Holder"},
+				Status:                form3.String("confirmed"),
+			},
+		},
+		{
+			name: "nil attributes",
+			want: []form3.FieldError{{Field: "attributes", Rule: form3.RuleRequired}},
+		},
+		{
+			name:       "required",
+			attributes: &form3.AccountAttributes{},
+			want: []form3.FieldError{
+				{Field: "country", Rule: form3.RuleRequired},
+				{Field: "name", Rule: form3.RuleRequired},
+			},
+		},
+		{
+			name: "patterns and codes",
+			attributes: &form3.AccountAttributes{
+				AccountNumber: "%$#@!123654",
+				BaseCurrency:  "XYZ",
+				Bic:           "NWBK",
+				Country:       form3.String("XX"),
+				Name:          []string{"Samantha Holder"},
+			},
+			want: []form3.FieldError{
+				{Field: "country", Rule: form3.RuleInvalid, Detail: "is not a valid ISO 3166-1 country code"},
+				{Field: "account_number", Rule: form3.RulePattern, Detail: "^[A-Z0-9]{0,64}$"},
+				{Field: "bic", Rule: form3.RulePattern, Detail: "^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$"},
+				{Field: "base_currency", Rule: form3.RuleInvalid, Detail: "is not a valid ISO 4217 currency code"},
+			},
+		},
+		{
+			name: "iban",
+			attributes: &form3.AccountAttributes{
+				Country: form3.String("IE"),
+				Iban:    "NL91ABNA0417164301",
+				Name:    []string{"Samantha Holder"},
+			},
+			want: []form3.FieldError{
+				{Field: "iban", Rule: form3.RuleInvalid, Detail: "is not a valid IBAN: invalid checksum"},
+			},
+		},
+		{
+			name: "iban of other country",
+			attributes: &form3.AccountAttributes{
+				Country: form3.String("IE"),
+				Iban:    "NL91ABNA0417164300",
+				Name:    []string{"Samantha Holder"},
+			},
+			want: []form3.FieldError{
+				{Field: "iban", Rule: form3.RuleInvalid, Detail: "does not match the account country"},
+			},
+		},
+		{
+			name: "bic of other country",
+			attributes: &form3.AccountAttributes{
+				Bic:     "ABNANL2A",
+				Country: form3.String("IE"),
+				Name:    []string{"Samantha Holder"},
+			},
+			want: []form3.FieldError{
+				{Field: "bic", Rule: form3.RuleInvalid, Detail: "does not match the account country"},
+			},
+		},
+		{
+			name: "bic of unknown country",
+			attributes: &form3.AccountAttributes{
+				Bic:     "ABNAXX2A",
+				Country: form3.String("IE"),
+				Name:    []string{"Samantha Holder"},
+			},
+			want: []form3.FieldError{
+				{Field: "bic", Rule: form3.RuleInvalid, Detail: "is not a valid BIC: unknown country XX"},
+			},
+		},
+		{
+			name: "names and enums",
+			attributes: &form3.AccountAttributes{
+				AccountClassification: form3.String("Corporate"),
+				Country:               form3.String("IE"),
+				Name:                  []string{strings.Repeat("a", 141), "b", "c", "d", "e"},
+				Status:                form3.String("closed"),
+			},
+			want: []form3.FieldError{
+				{Field: "name", Rule: form3.RuleMaxItems, Detail: "4"},
+				{Field: "name.0", Rule: form3.RuleMaxLength, Detail: "140"},
+				{Field: "account_classification", Rule: form3.RuleEnum, Detail: "Personal Business"},
+				{Field: "status", Rule: form3.RuleEnum, Detail: "pending confirmed failed"},
+			},
+		},
+		{
+			name: "length in characters",
+			attributes: &form3.AccountAttributes{
+				Country:                 form3.String("IE"),
+				Name:                    []string{strings.Repeat("ł", 140), strings.Repeat("ł", 141)},
+				SecondaryIdentification: strings.Repeat("Ł", 141),
+			},
+			want: []form3.FieldError{
+				{Field: "name.1", Rule: form3.RuleMaxLength, Detail: "140"},
+				{Field: "secondary_identification", Rule: form3.RuleMaxLength, Detail: "140"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.attributes.Validate()
+			if tc.want == nil {
+				if err != nil {
+					t.Errorf("err = %v; want: nil", err)
+				}
+				return
+			}
+
+			var validationError *form3.ValidationError
+			if !errors.As(err, &validationError) || !errors.Is(err, form3.ErrValidation) {
+				t.Fatalf("err = %v; want: ValidationError", err)
+			}
+			if diff := deep.Equal(validationError.FieldErrors, tc.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestAccountAttributes_ValidateSchema(t *testing.T) {
+	attributes := &form3.AccountAttributes{
+		BaseCurrency: "XYZ",
+		Country:      form3.String("XX"),
+		Name:         []string{"Samantha Holder"},
+		Status:       form3.String("closed"),
+	}
+
+	var validationError *form3.ValidationError
+	if !errors.As(attributes.ValidateSchema(), &validationError) {
+		t.Fatalf("err = %v; want: ValidationError", attributes.ValidateSchema())
+	}
+	// Only the schema of the API is checked, not the country and currency codes.
+	want := []form3.FieldError{{Field: "status", Rule: form3.RuleEnum, Detail: "pending confirmed failed"}}
+	if diff := deep.Equal(validationError.FieldErrors, want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestAccountsService_CreateWithValidation(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+
+	attributes := &form3.AccountAttributes{
+		AccountNumber: "%$#@!123654",
+		Country:       form3.String("IE"),
+		Name:          []string{"L. Mikolajczak"},
+	}
+
+	// The same field errors are reported by the client and by the API.
+	_, serverErr := server.Client().Accounts.Create(context.Background(), uuid.NewString(), attributes)
+	_, clientErr := server.Client(form3.WithValidation()).Accounts.Create(context.Background(), uuid.NewString(), attributes)
+
+	var f3Error *form3.F3Error
+	var validationError *form3.ValidationError
+	if !errors.As(serverErr, &f3Error) || !errors.As(clientErr, &validationError) {
+		t.Fatalf("errors = %v, %v; want: F3Error, ValidationError", serverErr, clientErr)
+	}
+	if diff := deep.Equal(validationError.FieldErrors, f3Error.FieldErrors); diff != nil {
+		t.Error(diff)
+	}
+	if got := server.Requests(); got != 1 {
+		t.Errorf("requests = %d; want: 1", got)
+	}
+}
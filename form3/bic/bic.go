@@ -0,0 +1,108 @@
+// Package bic implements parsing and validation of Business Identifier Codes
+// (ISO 9362), also known as SWIFT codes.
+package bic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned when a BIC is invalid.
+var (
+	ErrInvalidLength = errors.New("bic: must have 8 or 11 characters")
+	ErrInvalidFormat = errors.New("bic: invalid format")
+)
+
+// primaryOffice is the branch code of the primary office.
+const primaryOffice = "XXX"
+
+// BIC represents a parsed Business Identifier Code, e.g. NWBKGB22 or DEUTDEFF500.
+type BIC struct {
+	// Institution is the 4 letter code of the institution, e.g. NWBK.
+	Institution string
+	// Country is the ISO 3166-1 alpha-2 country code, e.g. GB.
+	Country string
+	// Location is the 2 character location code, e.g. 22.
+	Location string
+	// Branch is the optional 3 character branch code, e.g. 500.
+	Branch string
+}
+
+// Parse parses and validates the BIC. The code is normalised with Normalize first.
+func Parse(s string) (*BIC, error) {
+	s = Normalize(s)
+	if len(s) != 8 && len(s) != 11 {
+		return nil, ErrInvalidLength
+	}
+
+	b := &BIC{Institution: s[:4], Country: s[4:6], Location: s[6:8]}
+	if len(s) == 11 {
+		b.Branch = s[8:]
+	}
+	switch {
+	case !letters(b.Institution):
+		return nil, fmt.Errorf("%w: institution %s must consist of letters", ErrInvalidFormat, b.Institution)
+	case !letters(b.Country):
+		return nil, fmt.Errorf("%w: country %s must consist of letters", ErrInvalidFormat, b.Country)
+	case !alphanumerics(b.Location):
+		return nil, fmt.Errorf("%w: location %s must be alphanumeric", ErrInvalidFormat, b.Location)
+	case !alphanumerics(b.Branch):
+		return nil, fmt.Errorf("%w: branch %s must be alphanumeric", ErrInvalidFormat, b.Branch)
+	}
+	return b, nil
+}
+
+// Validate reports whether the BIC is valid.
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+// Normalize removes spaces from s and converts it to upper case.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// String returns the BIC as 8 or 11 characters long code.
+func (b *BIC) String() string {
+	return b.Institution + b.Country + b.Location + b.Branch
+}
+
+// BankCode returns the 6 character code identifying the institution in the country,
+// e.g. NWBKGB.
+func (b *BIC) BankCode() string {
+	return b.Institution + b.Country
+}
+
+// PrimaryOffice reports whether the BIC identifies the primary office of the
+// institution, i.e. it has no branch code or the branch code is XXX.
+func (b *BIC) PrimaryOffice() bool {
+	return b.Branch == "" || b.Branch == primaryOffice
+}
+
+// Test reports whether the BIC is a test code, i.e. the second character of the
+// location is 0.
+func (b *BIC) Test() bool {
+	return b.Location[1] == '0'
+}
+
+// letters reports whether s consists of upper case letters only.
+func letters(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// alphanumerics reports whether s consists of upper case letters and digits only.
+func alphanumerics(s string) bool {
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
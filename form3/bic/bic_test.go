@@ -0,0 +1,56 @@
+package bic_test
+
+import (
+	"errors"
+	"github.com/lmikolajczak/go-form3/form3/bic"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testcases := []struct {
+		input         string
+		want          bic.BIC
+		wantErr       error
+		wantPrimary   bool
+		wantNormalize string
+	}{
+		{input: "NWBKGB22", want: bic.BIC{Institution: "NWBK", Country: "GB", Location: "22"}, wantPrimary: true},
+		{input: "deutdeff500", want: bic.BIC{Institution: "DEUT", Country: "DE", Location: "FF", Branch: "500"}},
+		{input: "ABNA NL 2A XXX", want: bic.BIC{Institution: "ABNA", Country: "NL", Location: "2A", Branch: "XXX"}, wantPrimary: true},
+		{input: "NWBKGB2", wantErr: bic.ErrInvalidLength},
+		{input: "NWB1GB22", wantErr: bic.ErrInvalidFormat},
+		{input: "NWBK1B22", wantErr: bic.ErrInvalidFormat},
+		{input: "NWBKGB22-00", wantErr: bic.ErrInvalidFormat},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := bic.Parse(tc.input)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v; want: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if *got != tc.want {
+				t.Errorf("bic = %+v; want: %+v", *got, tc.want)
+			}
+			if got.PrimaryOffice() != tc.wantPrimary {
+				t.Errorf("primary office = %t; want: %t", got.PrimaryOffice(), tc.wantPrimary)
+			}
+		})
+	}
+}
+
+func TestBIC_String(t *testing.T) {
+	b, err := bic.Parse("deutdeff500")
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if got, want := b.String(), "DEUTDEFF500"; got != want {
+		t.Errorf("bic = %s; want: %s", got, want)
+	}
+	if got, want := b.BankCode(), "DEUTDE"; got != want {
+		t.Errorf("bank code = %s; want: %s", got, want)
+	}
+}
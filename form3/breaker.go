@@ -0,0 +1,264 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker of
+// the client is open.
+var ErrCircuitOpen = errors.New("form3: circuit breaker is open")
+
+// windowBuckets is the number of buckets of the sliding window of a circuit breaker.
+const windowBuckets = 10
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+// States of a circuit breaker.
+const (
+	// CircuitClosed lets all requests through and counts their failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast with ErrCircuitOpen until the cool-down passes.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through. The circuit
+	// closes if they succeed and opens again if any of them fails.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSettings describes when a circuit breaker opens and how it recovers.
+type CircuitBreakerSettings struct {
+	// Window is the duration of the sliding window the failure ratio is computed over.
+	Window time.Duration
+	// MinRequests is the minimum number of requests within the window needed to open
+	// the circuit.
+	MinRequests int
+	// FailureRatio is the ratio of failed requests within the window opening the circuit.
+	FailureRatio float64
+	// CoolDown is how long the circuit stays open before probe requests are let through.
+	CoolDown time.Duration
+	// Probes is the number of successful probe requests needed to close the circuit.
+	// It also limits the number of concurrent probe requests.
+	Probes int
+	// Failure reports whether the result of a request attempt counts as a failure.
+	// DefaultCircuitFailure is used when nil. Attempts cancelled by the caller count
+	// neither as failures nor as successes and are not reported to it.
+	Failure func(response *http.Response, err error) bool
+	// OnStateChange is called when the state of the circuit changes, e.g. to alert on
+	// the circuit opening.
+	OnStateChange func(from, to CircuitState)
+}
+
+// DefaultCircuitBreakerSettings returns circuit breaker settings with sensible
+// defaults: the circuit opens when at least half of at least 10 requests within 10s
+// fail and it is probed with a single request after 30s.
+func DefaultCircuitBreakerSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		Window:       10 * time.Second,
+		MinRequests:  10,
+		FailureRatio: 0.5,
+		CoolDown:     30 * time.Second,
+		Probes:       1,
+	}
+}
+
+// DefaultCircuitFailure reports transport errors, except cancellation by the caller,
+// and 5xx responses as failures.
+func DefaultCircuitFailure(response *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return response.StatusCode >= http.StatusInternalServerError
+}
+
+// WithCircuitBreaker makes the client check the circuit breaker before every attempt
+// of every request and report the result of the attempt to it. The circuit breaker
+// may be shared by several clients talking to the same API.
+func WithCircuitBreaker(breaker *CircuitBreaker) ClientOption {
+	return func(c *Client) {
+		c.circuitBreaker = breaker
+	}
+}
+
+// CircuitBreaker stops sending requests to the API while it is failing. It is safe
+// for concurrent use.
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+	now      func() time.Time
+
+	mu         sync.Mutex
+	state      CircuitState
+	generation uint64
+	buckets    [windowBuckets]bucket
+	openedAt   time.Time
+	probes     int
+	successes  int
+}
+
+// bucket counts the results of the requests within a part of the sliding window.
+type bucket struct {
+	epoch     int64
+	successes int
+	failures  int
+}
+
+// NewCircuitBreaker returns a new closed circuit breaker. Zero settings are replaced
+// with the defaults.
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	defaults := DefaultCircuitBreakerSettings()
+	if settings.Window <= 0 {
+		settings.Window = defaults.Window
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = defaults.MinRequests
+	}
+	if settings.FailureRatio <= 0 {
+		settings.FailureRatio = defaults.FailureRatio
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = defaults.CoolDown
+	}
+	if settings.Probes <= 0 {
+		settings.Probes = defaults.Probes
+	}
+	if settings.Failure == nil {
+		settings.Failure = DefaultCircuitFailure
+	}
+	return &CircuitBreaker{settings: settings, now: time.Now}
+}
+
+// State returns the current state of the circuit.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.settings.CoolDown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports with ErrCircuitOpen whether a request may be sent. Otherwise, it
+// returns the generation of the state the result of the request is reported for.
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	from := b.state
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.settings.CoolDown {
+		b.transition(CircuitHalfOpen)
+	}
+	var err error
+	switch b.state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.settings.Probes {
+			err = ErrCircuitOpen
+		} else {
+			b.probes++
+		}
+	}
+	generation, to := b.generation, b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return generation, err
+}
+
+// record reports the result of a request allowed in the given generation. An attempt
+// cancelled by the caller only releases its probe slot, so it does not close the circuit.
+func (b *CircuitBreaker) record(generation uint64, response *http.Response, err error) {
+	cancelled := errors.Is(err, context.Canceled)
+	failure := !cancelled && b.settings.Failure(response, err)
+
+	b.mu.Lock()
+	from := b.state
+	if generation == b.generation {
+		switch b.state {
+		case CircuitClosed:
+			if !cancelled {
+				b.count(failure)
+			}
+		case CircuitHalfOpen:
+			b.probes--
+			switch {
+			case cancelled:
+			case failure:
+				b.transition(CircuitOpen)
+			case b.successes+1 >= b.settings.Probes:
+				b.transition(CircuitClosed)
+			default:
+				b.successes++
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// count adds the result to the sliding window and opens the circuit if the failure
+// ratio is exceeded.
+func (b *CircuitBreaker) count(failure bool) {
+	width := int64(b.settings.Window / windowBuckets)
+	if width <= 0 {
+		width = 1
+	}
+	epoch := b.now().UnixNano() / width
+	current := &b.buckets[epoch%windowBuckets]
+	if current.epoch != epoch {
+		*current = bucket{epoch: epoch}
+	}
+	if failure {
+		current.failures++
+	} else {
+		current.successes++
+	}
+
+	var successes, failures int
+	for _, bucket := range b.buckets {
+		if bucket.epoch > epoch-windowBuckets {
+			successes, failures = successes+bucket.successes, failures+bucket.failures
+		}
+	}
+	total := successes + failures
+	if total >= b.settings.MinRequests && float64(failures)/float64(total) >= b.settings.FailureRatio {
+		b.transition(CircuitOpen)
+	}
+}
+
+// transition changes the state of the circuit and resets the counters.
+func (b *CircuitBreaker) transition(state CircuitState) {
+	b.state = state
+	b.generation++
+	b.probes, b.successes = 0, 0
+	b.buckets = [windowBuckets]bucket{}
+	if state == CircuitOpen {
+		b.openedAt = b.now()
+	}
+}
+
+// notify calls the state change callback if the state changed. It is called without
+// holding the lock, so the callback may use the circuit breaker.
+func (b *CircuitBreaker) notify(from, to CircuitState) {
+	if b.settings.OnStateChange == nil || from == to {
+		return
+	}
+	b.settings.OnStateChange(from, to)
+}
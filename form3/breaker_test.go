@@ -0,0 +1,169 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"github.com/go-test/deep"
+	"github.com/lmikolajczak/go-form3/form3"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+	breaker := form3.NewCircuitBreaker(form3.CircuitBreakerSettings{
+		Window:       time.Second,
+		MinRequests:  2,
+		FailureRatio: 0.5,
+		CoolDown:     50 * time.Millisecond,
+		OnStateChange: func(from, to form3.CircuitState) {
+			mu.Lock()
+			defer mu.Unlock()
+			transitions = append(transitions, from.String()+" -> "+to.String())
+		},
+	})
+
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithCircuitBreaker(breaker))
+	defer teardown()
+
+	statusCode, requests := http.StatusServiceUnavailable, 0
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(statusCode)
+		w.Write([]byte(`{"data":{"id":"ad27e265"}}`))
+	})
+	fetch := func() error {
+		_, err := f3.Accounts.Fetch(context.Background(), "ad27e265")
+		return err
+	}
+
+	// Two failures out of two requests open the circuit.
+	for i := 0; i < 2; i++ {
+		if err := fetch(); !errors.Is(err, form3.ErrServer) {
+			t.Fatalf("err = %v; want: %v", err, form3.ErrServer)
+		}
+	}
+	if err := fetch(); !errors.Is(err, form3.ErrCircuitOpen) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrCircuitOpen)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d; want: 2", requests)
+	}
+
+	// A failed probe opens the circuit again.
+	time.Sleep(60 * time.Millisecond)
+	if got := breaker.State(); got != form3.CircuitHalfOpen {
+		t.Errorf("state = %s; want: %s", got, form3.CircuitHalfOpen)
+	}
+	if err := fetch(); !errors.Is(err, form3.ErrServer) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrServer)
+	}
+	if err := fetch(); !errors.Is(err, form3.ErrCircuitOpen) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrCircuitOpen)
+	}
+
+	// A successful probe closes the circuit.
+	time.Sleep(60 * time.Millisecond)
+	statusCode = http.StatusOK
+	for i := 0; i < 2; i++ {
+		if err := fetch(); err != nil {
+			t.Errorf("err = %v; want: nil", err)
+		}
+	}
+	if got := breaker.State(); got != form3.CircuitClosed {
+		t.Errorf("state = %s; want: %s", got, form3.CircuitClosed)
+	}
+
+	want := []string{
+		"closed -> open",
+		"open -> half-open",
+		"half-open -> open",
+		"open -> half-open",
+		"half-open -> closed",
+	}
+	if diff := deep.Equal(transitions, want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestWithCircuitBreaker_FailureRatio(t *testing.T) {
+	breaker := form3.NewCircuitBreaker(form3.CircuitBreakerSettings{MinRequests: 4, FailureRatio: 0.5})
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithCircuitBreaker(breaker))
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		// Every third request fails, client errors do not count as failures.
+		requests++
+		switch {
+		case requests%3 == 0:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	for i := 0; i < 9; i++ {
+		_, err := f3.Accounts.Fetch(context.Background(), "ad27e265")
+		if errors.Is(err, form3.ErrCircuitOpen) {
+			t.Fatalf("err = %v; want: request sent", err)
+		}
+	}
+	if got := breaker.State(); got != form3.CircuitClosed {
+		t.Errorf("state = %s; want: %s", got, form3.CircuitClosed)
+	}
+}
+
+func TestWithCircuitBreaker_CancelledProbe(t *testing.T) {
+	breaker := form3.NewCircuitBreaker(form3.CircuitBreakerSettings{MinRequests: 1, CoolDown: 50 * time.Millisecond})
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithCircuitBreaker(breaker))
+	defer teardown()
+
+	var (
+		statusCode = http.StatusServiceUnavailable
+		started    chan struct{}
+	)
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		if started != nil {
+			// The probe hangs until it is cancelled by the caller.
+			close(started)
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(statusCode)
+		w.Write([]byte(`{"data":{"id":"ad27e265"}}`))
+	})
+
+	if _, err := f3.Accounts.Fetch(context.Background(), "ad27e265"); !errors.Is(err, form3.ErrServer) {
+		t.Fatalf("err = %v; want: %v", err, form3.ErrServer)
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	// A cancelled probe neither closes nor opens the circuit.
+	started = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := f3.Accounts.Fetch(ctx, "ad27e265"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v; want: %v", err, context.Canceled)
+	}
+	if got := breaker.State(); got != form3.CircuitHalfOpen {
+		t.Errorf("state = %s; want: %s", got, form3.CircuitHalfOpen)
+	}
+
+	// The probe slot is released for the next probe.
+	started, statusCode = nil, http.StatusOK
+	if _, err := f3.Accounts.Fetch(context.Background(), "ad27e265"); err != nil {
+		t.Errorf("err = %v; want: nil", err)
+	}
+	if got := breaker.State(); got != form3.CircuitClosed {
+		t.Errorf("state = %s; want: %s", got, form3.CircuitClosed)
+	}
+}
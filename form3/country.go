@@ -0,0 +1,299 @@
+package form3
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lmikolajczak/go-form3/form3/bic"
+	"github.com/lmikolajczak/go-form3/form3/modulus"
+	"regexp"
+	"sync"
+)
+
+// Requirement describes whether an account attribute is required by a country.
+type Requirement int
+
+const (
+	// Optional attributes may be provided.
+	Optional Requirement = iota
+	// Required attributes must be provided.
+	Required
+	// NotSupported attributes must not be provided.
+	NotSupported
+)
+
+// FieldRule describes the requirement and format of a single account attribute.
+type FieldRule struct {
+	Requirement Requirement
+	// Pattern the attribute must match, if not empty.
+	Pattern string
+}
+
+// CountryRule describes which account attributes are required or not supported in
+// a country and what their formats are.
+type CountryRule struct {
+	BankID        FieldRule
+	BankIDCode    FieldRule
+	Bic           FieldRule
+	AccountNumber FieldRule
+	Iban          FieldRule
+
+	// DefaultBankIDCode is the only accepted bank_id_code in the country, e.g. GBDSC.
+	DefaultBankIDCode string
+	// DefaultBaseCurrency is the currency of accounts in the country, e.g. GBP.
+	DefaultBaseCurrency string
+	// Check is an optional additional check of the attributes, e.g. of the bank_id
+	// and account_number combination. The GB modulus check only covers the sort
+	// codes of the table installed with modulus.SetDefault.
+	Check func(a *AccountAttributes) []FieldError
+}
+
+// countryRules is the registry of the country rules keyed by ISO 3166-1 country code.
+var countryRules = struct {
+	sync.RWMutex
+	rules    map[string]CountryRule
+	patterns map[string]*regexp.Regexp
+}{
+	rules:    map[string]CountryRule{},
+	patterns: map[string]*regexp.Regexp{},
+}
+
+// RegisterCountryRule registers the rule of the given country, replacing the rule
+// registered before. It panics if any of the patterns is invalid.
+func RegisterCountryRule(country string, rule CountryRule) {
+	countryRules.Lock()
+	defer countryRules.Unlock()
+	for _, field := range []FieldRule{rule.BankID, rule.BankIDCode, rule.Bic, rule.AccountNumber, rule.Iban} {
+		if _, ok := countryRules.patterns[field.Pattern]; field.Pattern != "" && !ok {
+			countryRules.patterns[field.Pattern] = regexp.MustCompile(field.Pattern)
+		}
+	}
+	countryRules.rules[country] = rule
+}
+
+// LookupCountryRule returns the rule registered for the given country.
+func LookupCountryRule(country string) (CountryRule, bool) {
+	countryRules.RLock()
+	defer countryRules.RUnlock()
+	rule, ok := countryRules.rules[country]
+	return rule, ok
+}
+
+// ApplyCountryDefaults fills in the attributes derived from the country that are
+// not set, i.e. bank_id_code and base_currency. A valid BIC is normalised and, if
+// the country is not set, the country is taken from the BIC.
+func (a *AccountAttributes) ApplyCountryDefaults() {
+	if b, err := bic.Parse(a.Bic); err == nil {
+		a.Bic = b.String()
+		if a.Country == nil {
+			a.Country = String(b.Country)
+		}
+	}
+	if a.Country == nil {
+		return
+	}
+	rule, ok := LookupCountryRule(*a.Country)
+	if !ok {
+		return
+	}
+	if a.BankIDCode == "" && rule.BankIDCode.Requirement != NotSupported {
+		a.BankIDCode = rule.DefaultBankIDCode
+	}
+	if a.BaseCurrency == "" {
+		a.BaseCurrency = rule.DefaultBaseCurrency
+	}
+}
+
+// validateCountry returns field errors of the attributes according to the rule of
+// their country. Attributes of countries without a registered rule are not checked.
+func (a *AccountAttributes) validateCountry() []FieldError {
+	if a.Country == nil {
+		return nil
+	}
+	country := *a.Country
+	rule, ok := LookupCountryRule(country)
+	if !ok {
+		return nil
+	}
+
+	fields := []struct {
+		name  string
+		value string
+		rule  FieldRule
+	}{
+		{"bank_id", a.BankID, rule.BankID},
+		{"bank_id_code", a.BankIDCode, rule.BankIDCode},
+		{"bic", a.Bic, rule.Bic},
+		{"account_number", a.AccountNumber, rule.AccountNumber},
+		{"iban", a.Iban, rule.Iban},
+	}
+
+	var fieldErrors []FieldError
+	for _, field := range fields {
+		switch {
+		case field.value == "" && field.rule.Requirement == Required:
+			fieldErrors = append(fieldErrors, FieldError{Field: field.name, Rule: RuleRequired})
+		case field.value != "" && field.rule.Requirement == NotSupported:
+			fieldErrors = append(fieldErrors, FieldError{Field: field.name, Rule: RuleNotSupported, Detail: country})
+		case field.value != "" && field.rule.Pattern != "":
+			countryRules.RLock()
+			pattern := countryRules.patterns[field.rule.Pattern]
+			countryRules.RUnlock()
+			if !pattern.MatchString(field.value) {
+				fieldErrors = append(fieldErrors, FieldError{Field: field.name, Rule: RulePattern, Detail: field.rule.Pattern})
+			}
+		}
+	}
+	if a.BankIDCode != "" && rule.DefaultBankIDCode != "" && a.BankIDCode != rule.DefaultBankIDCode {
+		fieldErrors = append(fieldErrors, FieldError{Field: "bank_id_code", Rule: RuleEnum, Detail: rule.DefaultBankIDCode})
+	}
+	if rule.Check != nil {
+		fieldErrors = append(fieldErrors, rule.Check(a)...)
+	}
+	return fieldErrors
+}
+
+// checkGBAccount checks the sort code and account number combination with the
+// modulus check. Combinations in invalid format are reported by the field patterns.
+//
+// The check uses modulus.Default. The embedded sample table only covers a few sort
+// code ranges, so real sort codes pass unchecked until the current Vocalink table is
+// installed with modulus.SetDefault.
+func checkGBAccount(a *AccountAttributes) []FieldError {
+	if a.BankID == "" || a.AccountNumber == "" {
+		return nil
+	}
+	if err := modulus.CheckGBAccount(a.BankID, a.AccountNumber); errors.Is(err, modulus.ErrInvalidAccount) {
+		detail := fmt.Sprintf("does not pass the modulus check for sort code %s", a.BankID)
+		return []FieldError{{Field: "account_number", Rule: RuleInvalid, Detail: detail}}
+	}
+	return nil
+}
+
+func init() {
+	for country, rule := range map[string]CountryRule{
+		"AU": {
+			BankID:              FieldRule{Optional, `^[0-9]{6}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			Bic:                 FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{6,10}$`},
+			Iban:                FieldRule{NotSupported, ""},
+			DefaultBankIDCode:   "AUBSB",
+			DefaultBaseCurrency: "AUD",
+		},
+		"BE": {
+			BankID:              FieldRule{Required, `^[0-9]{3}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{7}$`},
+			DefaultBankIDCode:   "BEBAC",
+			DefaultBaseCurrency: "EUR",
+		},
+		"CA": {
+			BankID:              FieldRule{Optional, `^0[0-9]{8}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			Bic:                 FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{7,12}$`},
+			Iban:                FieldRule{NotSupported, ""},
+			DefaultBankIDCode:   "CACPA",
+			DefaultBaseCurrency: "CAD",
+		},
+		"CH": {
+			BankID:              FieldRule{Required, `^[0-9]{5}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{12}$`},
+			DefaultBankIDCode:   "CHBCC",
+			DefaultBaseCurrency: "CHF",
+		},
+		"DE": {
+			BankID:              FieldRule{Required, `^[0-9]{8}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{7,10}$`},
+			DefaultBankIDCode:   "DEBLZ",
+			DefaultBaseCurrency: "EUR",
+		},
+		"ES": {
+			BankID:              FieldRule{Required, `^[0-9]{8}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{10}$`},
+			DefaultBankIDCode:   "ESNCC",
+			DefaultBaseCurrency: "EUR",
+		},
+		"FR": {
+			BankID:              FieldRule{Required, `^[0-9]{10}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9A-Z]{10}$`},
+			DefaultBankIDCode:   "FR",
+			DefaultBaseCurrency: "EUR",
+		},
+		"GB": {
+			BankID:              FieldRule{Required, `^[0-9]{6}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			Bic:                 FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{8}$`},
+			DefaultBankIDCode:   "GBDSC",
+			DefaultBaseCurrency: "GBP",
+			Check:               checkGBAccount,
+		},
+		"GR": {
+			BankID:              FieldRule{Required, `^[0-9]{7}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{16}$`},
+			DefaultBankIDCode:   "GRBIC",
+			DefaultBaseCurrency: "EUR",
+		},
+		"HK": {
+			BankID:              FieldRule{Optional, `^[0-9]{3}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			Bic:                 FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{9,12}$`},
+			Iban:                FieldRule{NotSupported, ""},
+			DefaultBankIDCode:   "HKNCC",
+			DefaultBaseCurrency: "HKD",
+		},
+		"IT": {
+			BankID:              FieldRule{Required, `^[0-9]{10,11}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{12}$`},
+			DefaultBankIDCode:   "ITNCC",
+			DefaultBaseCurrency: "EUR",
+		},
+		"LU": {
+			BankID:              FieldRule{Required, `^[0-9]{3}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9A-Z]{13}$`},
+			DefaultBankIDCode:   "LULUX",
+			DefaultBaseCurrency: "EUR",
+		},
+		"NL": {
+			BankID:              FieldRule{NotSupported, ""},
+			BankIDCode:          FieldRule{NotSupported, ""},
+			Bic:                 FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{10}$`},
+			DefaultBaseCurrency: "EUR",
+		},
+		"PL": {
+			BankID:              FieldRule{Required, `^[0-9]{8}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{16}$`},
+			DefaultBankIDCode:   "PLKNR",
+			DefaultBaseCurrency: "PLN",
+		},
+		"PT": {
+			BankID:              FieldRule{Required, `^[0-9]{8}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{11}$`},
+			DefaultBankIDCode:   "PTNCC",
+			DefaultBaseCurrency: "EUR",
+		},
+		"US": {
+			BankID:              FieldRule{Required, `^[0-9]{9}$`},
+			BankIDCode:          FieldRule{Required, ""},
+			Bic:                 FieldRule{Required, ""},
+			AccountNumber:       FieldRule{Optional, `^[0-9]{6,17}$`},
+			Iban:                FieldRule{NotSupported, ""},
+			DefaultBankIDCode:   "USABA",
+			DefaultBaseCurrency: "USD",
+		},
+	} {
+		RegisterCountryRule(country, rule)
+	}
+}
@@ -0,0 +1,118 @@
+package form3_test
+
+import (
+	"errors"
+	"github.com/go-test/deep"
+	"github.com/lmikolajczak/go-form3/form3"
+	"testing"
+)
+
+func TestAccountAttributes_ValidateCountry(t *testing.T) {
+	testcases := []struct {
+		name       string
+		attributes *form3.AccountAttributes
+		want       []form3.FieldError
+	}{
+		{
+			name: "valid GB account",
+			attributes: &form3.AccountAttributes{
+				AccountNumber: "41426819",
+				BankID:        "400300",
+				BankIDCode:    "GBDSC",
+				Bic:           "NWBKGB22",
+				Country:       form3.String("GB"),
+				Name:          []string{"Samantha Holder"},
+			},
+		},
+		{
+			name: "invalid GB account",
+			attributes: &form3.AccountAttributes{
+				AccountNumber: "4142681",
+				BankIDCode:    "DEBLZ",
+				Country:       form3.String("GB"),
+				Name:          []string{"Samantha Holder"},
+			},
+			want: []form3.FieldError{
+				{Field: "bank_id", Rule: form3.RuleRequired},
+				{Field: "bic", Rule: form3.RuleRequired},
+				{Field: "account_number", Rule: form3.RulePattern, Detail: "^[0-9]{8}$"},
+				{Field: "bank_id_code", Rule: form3.RuleEnum, Detail: "GBDSC"},
+			},
+		},
+		{
+			name: "GB account failing modulus check",
+			attributes: &form3.AccountAttributes{
+				AccountNumber: "66374959",
+				BankID:        "089999",
+				BankIDCode:    "GBDSC",
+				Bic:           "NWBKGB22",
+				Country:       form3.String("GB"),
+				Name:          []string{"Samantha Holder"},
+			},
+			want: []form3.FieldError{
+				{Field: "account_number", Rule: form3.RuleInvalid, Detail: "does not pass the modulus check for sort code 089999"},
+			},
+		},
+		{
+			name: "not supported in NL",
+			attributes: &form3.AccountAttributes{
+				BankID:     "ABNA",
+				BankIDCode: "ABNANL",
+				Bic:        "ABNANL2A",
+				Country:    form3.String("NL"),
+				Name:       []string{"L. Mikolajczak"},
+			},
+			want: []form3.FieldError{
+				{Field: "bank_id", Rule: form3.RuleNotSupported, Detail: "NL"},
+				{Field: "bank_id_code", Rule: form3.RuleNotSupported, Detail: "NL"},
+			},
+		},
+		{
+			name: "country without rule",
+			attributes: &form3.AccountAttributes{
+				BankID:  "ANYTHING",
+				Country: form3.String("IE"),
+				Name:    []string{"L. Mikolajczak"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.attributes.Validate()
+			var validationError *form3.ValidationError
+			if tc.want == nil {
+				if err != nil {
+					t.Errorf("err = %v; want: nil", err)
+				}
+				return
+			}
+			if !errors.As(err, &validationError) {
+				t.Fatalf("err = %v; want: ValidationError", err)
+			}
+			if diff := deep.Equal(validationError.FieldErrors, tc.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestAccountAttributes_ApplyCountryDefaults(t *testing.T) {
+	attributes := &form3.AccountAttributes{Country: form3.String("DE")}
+	attributes.ApplyCountryDefaults()
+	if attributes.BankIDCode != "DEBLZ" || attributes.BaseCurrency != "EUR" {
+		t.Errorf("bank_id_code, base_currency = %s, %s; want: DEBLZ, EUR", attributes.BankIDCode, attributes.BaseCurrency)
+	}
+
+	attributes = &form3.AccountAttributes{Country: form3.String("NL"), BaseCurrency: "USD"}
+	attributes.ApplyCountryDefaults()
+	if attributes.BankIDCode != "" || attributes.BaseCurrency != "USD" {
+		t.Errorf("bank_id_code, base_currency = %s, %s; want: empty, USD", attributes.BankIDCode, attributes.BaseCurrency)
+	}
+
+	attributes = &form3.AccountAttributes{Bic: "deut de ff 500"}
+	attributes.ApplyCountryDefaults()
+	if attributes.Bic != "DEUTDEFF500" || attributes.Country == nil || *attributes.Country != "DE" || attributes.BankIDCode != "DEBLZ" {
+		t.Errorf("bic, country, bank_id_code = %s, %v, %s; want: DEUTDEFF500, DE, DEBLZ", attributes.Bic, attributes.Country, attributes.BankIDCode)
+	}
+}
@@ -0,0 +1,113 @@
+package form3
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors matched by F3Error with errors.Is, e.g.:
+//
+//	if errors.Is(err, form3.ErrNotFound) {
+//		// ...
+//	}
+var (
+	ErrNotFound     = errors.New("form3: not found")
+	ErrConflict     = errors.New("form3: conflict")
+	ErrValidation   = errors.New("form3: validation failed")
+	ErrRateLimited  = errors.New("form3: rate limited")
+	ErrUnauthorized = errors.New("form3: unauthorized")
+	ErrServer       = errors.New("form3: server error")
+)
+
+// F3Error represents an error returned from Form3 REST API.
+type F3Error struct {
+	StatusCode   int    `json:"-"`
+	ErrorCode    int    `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+
+	// Method and URL of the request that failed.
+	Method string `json:"-"`
+	URL    string `json:"-"`
+	// RequestID is the value of the X-Request-Id header, if available.
+	RequestID string `json:"-"`
+	// FieldErrors holds validation failures parsed from the ErrorMessage, if any.
+	FieldErrors []FieldError `json:"-"`
+}
+
+// Error returns a string representation of the F3Error.
+func (e F3Error) Error() string {
+	return fmt.Sprintf("http %d: code: %d, message=%s", e.StatusCode, e.ErrorCode, e.ErrorMessage)
+}
+
+// Is reports whether the error matches the target sentinel error based on the status code.
+func (e F3Error) Is(target error) bool {
+	switch target {
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// Temporary reports whether the error is caused by a transient condition, such as
+// rate limiting or an unavailable upstream, that is likely to clear on its own.
+func (e F3Error) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Retryable reports whether repeating the same request may succeed. Apart from
+// temporary errors, it includes internal server errors.
+func (e F3Error) Retryable() bool {
+	return e.Temporary() || e.StatusCode == http.StatusInternalServerError
+}
+
+// TransportError represents an error that occurred while sending the request or
+// reading the response, before a response from the Form3 REST API was decoded.
+type TransportError struct {
+	Method string
+	URL    string
+	Err    error
+}
+
+// Error returns a string representation of the TransportError.
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("form3: %s %s: %v", e.Method, e.URL, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TransportError) Unwrap() error { return e.Err }
+
+// Temporary reports whether the underlying error is transient.
+func (e *TransportError) Temporary() bool { return DefaultRetryableError(e.Err) }
+
+// Retryable reports whether repeating the same request may succeed.
+func (e *TransportError) Retryable() bool { return DefaultRetryableError(e.Err) }
+
+// DecodeError represents an error that occurred while decoding the response body.
+type DecodeError struct {
+	Body []byte
+	Err  error
+}
+
+// Error returns a string representation of the DecodeError.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("json %s, error: %v", string(e.Body), e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error { return e.Err }
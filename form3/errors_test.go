@@ -0,0 +1,133 @@
+package form3_test
+
+import (
+	"errors"
+	"github.com/lmikolajczak/go-form3/form3"
+	"net/http"
+	"testing"
+)
+
+func TestF3Error_Is(t *testing.T) {
+	sentinels := []error{
+		form3.ErrNotFound,
+		form3.ErrConflict,
+		form3.ErrValidation,
+		form3.ErrRateLimited,
+		form3.ErrUnauthorized,
+		form3.ErrServer,
+	}
+
+	testcases := []struct {
+		statusCode    int
+		want          error
+		wantRetryable bool
+	}{
+		{statusCode: http.StatusBadRequest, want: form3.ErrValidation},
+		{statusCode: http.StatusUnauthorized, want: form3.ErrUnauthorized},
+		{statusCode: http.StatusNotFound, want: form3.ErrNotFound},
+		{statusCode: http.StatusConflict, want: form3.ErrConflict},
+		{statusCode: http.StatusTooManyRequests, want: form3.ErrRateLimited, wantRetryable: true},
+		{statusCode: http.StatusInternalServerError, want: form3.ErrServer, wantRetryable: true},
+		{statusCode: http.StatusServiceUnavailable, want: form3.ErrServer, wantRetryable: true},
+		{statusCode: http.StatusTeapot},
+	}
+
+	for _, tc := range testcases {
+		t.Run(http.StatusText(tc.statusCode), func(t *testing.T) {
+			var err error = &form3.F3Error{StatusCode: tc.statusCode}
+			for _, sentinel := range sentinels {
+				if got, want := errors.Is(err, sentinel), sentinel == tc.want; got != want {
+					t.Errorf("errors.Is(%v) = %t; want: %t", sentinel, got, want)
+				}
+			}
+			if got := err.(*form3.F3Error).Retryable(); got != tc.wantRetryable {
+				t.Errorf("retryable = %t; want: %t", got, tc.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestClient_RequestErrors(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t)
+	defer teardown()
+
+	mux.HandleFunc("/not-found", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "request-id")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error_message":"record does not exist"}`))
+	})
+	mux.HandleFunc("/bad-gateway", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`<html>Bad Gateway</html>`))
+	})
+	mux.HandleFunc("/invalid-json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":`))
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		request, _ := f3.NewRequest(http.MethodGet, "/not-found", nil)
+		err := f3.Request(nil, request, nil)
+
+		var f3Error *form3.F3Error
+		if !errors.As(err, &f3Error) || !errors.Is(err, form3.ErrNotFound) {
+			t.Fatalf("err = %v; want: not found", err)
+		}
+		if f3Error.Method != http.MethodGet || f3Error.URL != f3.BaseURL()+"/not-found" {
+			t.Errorf("request = %s %s; want: GET %s/not-found", f3Error.Method, f3Error.URL, f3.BaseURL())
+		}
+		if f3Error.RequestID != "request-id" {
+			t.Errorf("request id = %s; want: request-id", f3Error.RequestID)
+		}
+	})
+
+	t.Run("non json api error", func(t *testing.T) {
+		request, _ := f3.NewRequest(http.MethodGet, "/bad-gateway", nil)
+		err := f3.Request(nil, request, nil)
+
+		var f3Error *form3.F3Error
+		if !errors.As(err, &f3Error) || !f3Error.Temporary() {
+			t.Fatalf("err = %v; want: temporary F3Error", err)
+		}
+		if got, want := f3Error.ErrorMessage, "<html>Bad Gateway</html>"; got != want {
+			t.Errorf("message = %s; want: %s", got, want)
+		}
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		request, _ := f3.NewRequest(http.MethodGet, "/invalid-json", nil)
+		err := f3.Request(&struct{}{}, request, nil)
+
+		var decodeError *form3.DecodeError
+		if !errors.As(err, &decodeError) {
+			t.Errorf("err = %v; want: DecodeError", err)
+		}
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		request, _ := f3.NewRequest(http.MethodGet, "/not-found", nil)
+		request.URL.Host = "localhost:1"
+		err := f3.Request(nil, request, nil)
+
+		var transportError *form3.TransportError
+		if !errors.As(err, &transportError) || transportError.Method != http.MethodGet {
+			t.Fatalf("err = %v; want: TransportError", err)
+		}
+		if !transportError.Retryable() {
+			t.Errorf("retryable = false; want: true")
+		}
+	})
+
+	t.Run("permanent transport error", func(t *testing.T) {
+		request, _ := f3.NewRequest(http.MethodGet, "/not-found", nil)
+		request.URL.Scheme = "foo"
+		err := f3.Request(nil, request, nil)
+
+		var transportError *form3.TransportError
+		if !errors.As(err, &transportError) {
+			t.Fatalf("err = %v; want: TransportError", err)
+		}
+		if transportError.Temporary() || transportError.Retryable() {
+			t.Errorf("retryable = true; want: false")
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package form3
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// UnmarshalJSON parses JSON-encoded account. Unrecognised fields are kept in Extra.
+func (a *Account) UnmarshalJSON(data []byte) error {
+	type account Account
+	extra, err := unmarshalExtra(data, (*account)(a))
+	if err != nil {
+		return err
+	}
+	a.Extra = extra
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of the account including fields kept in Extra.
+func (a Account) MarshalJSON() ([]byte, error) {
+	type account Account
+	return marshalExtra(account(a), a.Extra)
+}
+
+// UnmarshalJSON parses JSON-encoded account attributes. Unrecognised fields are kept
+// in Extra.
+func (a *AccountAttributes) UnmarshalJSON(data []byte) error {
+	type accountAttributes AccountAttributes
+	extra, err := unmarshalExtra(data, (*accountAttributes)(a))
+	if err != nil {
+		return err
+	}
+	a.Extra = extra
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of the account attributes including fields
+// kept in Extra.
+func (a AccountAttributes) MarshalJSON() ([]byte, error) {
+	type accountAttributes AccountAttributes
+	return marshalExtra(accountAttributes(a), a.Extra)
+}
+
+// unmarshalExtra parses JSON-encoded object into the struct pointed by v and returns
+// the fields that v does not declare.
+func unmarshalExtra(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	known := knownFields(reflect.TypeOf(v).Elem())
+	for name := range fields {
+		if isKnownField(known, name) {
+			delete(fields, name)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// marshalExtra returns the JSON encoding of the struct v merged with the extra fields.
+// Fields declared by v take precedence.
+func marshalExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	known := knownFields(reflect.TypeOf(v))
+	for name, value := range extra {
+		if !isKnownField(known, name) {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// knownFieldsCache caches JSON field names of the struct types.
+var knownFieldsCache sync.Map
+
+// knownFields returns the JSON field names declared by the struct type t.
+func knownFields(t reflect.Type) map[string]struct{} {
+	if fields, ok := knownFieldsCache.Load(t); ok {
+		return fields.(map[string]struct{})
+	}
+
+	fields := map[string]struct{}{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case name == "-" || !field.IsExported():
+			continue
+		case name == "":
+			name = field.Name
+		}
+		fields[name] = struct{}{}
+	}
+	knownFieldsCache.Store(t, fields)
+	return fields
+}
+
+// isKnownField reports whether the name matches any of the known field names. Like
+// encoding/json, the names are matched case-insensitively.
+func isKnownField(known map[string]struct{}, name string) bool {
+	if _, ok := known[name]; ok {
+		return true
+	}
+	for field := range known {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+	return false
+}
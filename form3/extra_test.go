@@ -0,0 +1,85 @@
+package form3_test
+
+import (
+	"encoding/json"
+	"github.com/lmikolajczak/go-form3/form3"
+	"testing"
+)
+
+func TestAccount_JSONRoundTrip(t *testing.T) {
+	testcases := []struct {
+		name      string
+		json      string
+		wantExtra int
+		wantAttrs int
+	}{
+		{
+			name:      "known fields only",
+			json:      `{"attributes":{"country":"GB","private_identification":{"birth_date":"2017-07-23","identification":"13YH458762"}},"id":"ad27e265","type":"accounts","version":0}`,
+			wantExtra: 0,
+			wantAttrs: 0,
+		},
+		{
+			name:      "unknown fields",
+			json:      `{"attributes":{"country":"GB","new_attribute":{"nested":[1,2]},"other":"value"},"id":"ad27e265","new_field":true,"type":"accounts"}`,
+			wantExtra: 1,
+			wantAttrs: 2,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			account := new(form3.Account)
+			if err := json.Unmarshal([]byte(tc.json), account); err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+			if got := len(account.Extra); got != tc.wantExtra {
+				t.Errorf("len(extra) = %d; want: %d", got, tc.wantExtra)
+			}
+			if got := len(account.Attributes.Extra); got != tc.wantAttrs {
+				t.Errorf("len(attributes.extra) = %d; want: %d", got, tc.wantAttrs)
+			}
+
+			got, err := json.Marshal(account)
+			if err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+			if string(got) != tc.json {
+				t.Errorf("json = %s; want: %s", got, tc.json)
+			}
+		})
+	}
+}
+
+func TestAccount_UnmarshalJSONMixedCase(t *testing.T) {
+	account := new(form3.Account)
+	if err := json.Unmarshal([]byte(`{"ID":"ad27e265","Attributes":{"Country":"GB"}}`), account); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if account.Extra != nil || account.Attributes.Extra != nil {
+		t.Errorf("extra = %v, attributes.extra = %v; want: nil", account.Extra, account.Attributes.Extra)
+	}
+
+	got, err := json.Marshal(account)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if want := `{"attributes":{"country":"GB"},"id":"ad27e265"}`; string(got) != want {
+		t.Errorf("json = %s; want: %s", got, want)
+	}
+}
+
+func TestAccount_MarshalJSONKnownFieldsWin(t *testing.T) {
+	account := form3.Account{
+		ID:    "ad27e265",
+		Extra: map[string]json.RawMessage{"id": json.RawMessage(`"overridden"`), "ID": json.RawMessage(`"overridden"`)},
+	}
+
+	got, err := json.Marshal(account)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if want := `{"id":"ad27e265"}`; string(got) != want {
+		t.Errorf("json = %s; want: %s", got, want)
+	}
+}
@@ -1,13 +1,18 @@
 // Package form3 is a simple Form3 REST API client.
 //
-// Currently, it implements Fetch, Create and Delete actions on the Account resource.
+// Resources are exposed as services on the client, e.g. Client.Accounts implements
+// List, Fetch, Create and Delete actions on the Account resource.
 // For more info check: https://api-docs.form3.tech/api.html.
 package form3
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"io"
 	"net/http"
 	"time"
@@ -28,24 +33,68 @@ func WithHTTPClient(httpClient HTTPClient) ClientOption {
 	}
 }
 
+// WithIDGenerator allows to set a function generating identifiers of the created
+// resources. By default, random UUIDs are used.
+func WithIDGenerator(generator func() string) ClientOption {
+	return func(c *Client) {
+		c.idGenerator = generator
+	}
+}
+
 // Client represents Form3 REST API client.
 type Client struct {
-	baseURL    string
-	httpClient HTTPClient
+	baseURL     string
+	httpClient  HTTPClient
+	retryPolicy RetryPolicy
+	idGenerator func() string
+	validate    bool
+	middlewares []Middleware
+	signer      Signer
+	tokenSource TokenSource
+	tlsOptions  []func(*tls.Config) error
+	rateLimiter *RateLimiter
+	// operationRateLimiters limit requests of particular operations.
+	operationRateLimiters map[Operation]*RateLimiter
+	circuitBreaker        *CircuitBreaker
+	// err is the error of the client configuration returned by every request.
+	err error
+
+	// Services used for talking to different resources of the Form3 REST API.
+	Accounts *AccountsService
 }
 
+// service is shared by all resource services. It gives them access to the client.
+type service struct {
+	client *Client
+}
+
+// DefaultTimeout bounds the requests made by the deprecated methods taking no context
+// and the token requests of ClientCredentialsTokenSource.
+const DefaultTimeout = 15 * time.Second
+
 // NewClient returns a new Form3 REST API client.
+//
+// The default HTTP client has no timeout of its own, so requests are bounded only by
+// the deadlines of their contexts. Requests made with a context without a deadline
+// may therefore wait forever for an unresponsive server.
 func NewClient(baseURL string, options ...ClientOption) *Client {
 	c := &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
-		},
+		baseURL:     baseURL,
+		httpClient:  &http.Client{},
+		idGenerator: uuid.NewString,
 	}
 
 	for _, option := range options {
 		option(c)
 	}
+	switch {
+	case c.signer != nil && c.tokenSource != nil:
+		c.err = errors.New("form3: WithSigner cannot be combined with WithTokenSource")
+	case len(c.tlsOptions) > 0:
+		c.err = c.configureTLS()
+	}
+
+	c.Accounts = &AccountsService{client: c}
 
 	return c
 }
@@ -56,61 +105,165 @@ func (c *Client) BaseURL() string {
 }
 
 // NewRequest returns new http request with given method, endpoint and payload.
+//
+// It is a shortcut for NewRequestWithContext with context.Background.
 func (c *Client) NewRequest(method, endpoint string, payload interface{}) (*http.Request, error) {
+	return c.NewRequestWithContext(context.Background(), method, endpoint, payload)
+}
+
+// NewRequestWithContext returns new http request with given context, method, endpoint
+// and payload. The context controls the entire lifetime of the request. The encoded
+// payload can be replayed, so the request may be safely retried.
+func (c *Client) NewRequestWithContext(ctx context.Context, method, endpoint string, payload interface{}) (*http.Request, error) {
 	switch payload.(type) {
 	case nil:
-		return http.NewRequest(method, c.BaseURL()+endpoint, http.NoBody)
+		return http.NewRequestWithContext(ctx, method, c.BaseURL()+endpoint, http.NoBody)
 	default:
 		body, err := c.marshal(payload)
 		if err != nil {
 			return nil, err
 		}
-		return http.NewRequest(method, c.BaseURL()+endpoint, bytes.NewBuffer(body))
+		return http.NewRequestWithContext(ctx, method, c.BaseURL()+endpoint, bytes.NewBuffer(body))
 	}
 }
 
-// F3Error represents an error returned from Form3 REST API.
-type F3Error struct {
-	StatusCode   int    `json:"-"`
-	ErrorCode    int    `json:"error_code,omitempty"`
-	ErrorMessage string `json:"error_message,omitempty"`
+// RequestWithContext makes a http request to the Form3 REST API using the given
+// context instead of the one attached to the request.
+func (c *Client) RequestWithContext(ctx context.Context, v interface{}, request *http.Request, headers map[string]string) error {
+	return c.Request(v, request.WithContext(ctx), headers)
 }
 
-// Error returns a string representation of the F3Error.
-func (e F3Error) Error() string {
-	return fmt.Sprintf("http %d: code: %d, message=%s", e.StatusCode, e.ErrorCode, e.ErrorMessage)
+// Request makes a http request to the Form3 REST API. Cancellation and deadlines
+// are taken from the request's context. Failed attempts are retried according to
+// the client's RetryPolicy. The request passes through the client's middlewares.
+func (c *Client) Request(v interface{}, request *http.Request, headers map[string]string) error {
+	return c.request("", v, request, headers)
 }
 
-// Request makes a http request to the Form3 REST API.
-func (c *Client) Request(v interface{}, request *http.Request, headers map[string]string) error {
+// request makes a http request performing the given operation.
+func (c *Client) request(operation Operation, v interface{}, request *http.Request, headers map[string]string) error {
+	if c.err != nil {
+		return c.err
+	}
 	for key, value := range headers {
 		request.Header.Set(key, value)
 	}
 
+	_, err := c.chain(c.send(v))(&Call{Operation: operation, Request: request})
+	return err
+}
+
+// send returns the innermost handler of the middleware chain. It sends the request,
+// retrying failed attempts, and decodes the response into v.
+func (c *Client) send(v interface{}) Handler {
+	return func(call *Call) (*http.Response, error) {
+		request, reauthorized := call.Request, false
+		limiters := c.rateLimiters(call.Operation)
+		for attempt := 1; ; attempt++ {
+			for _, limiter := range limiters {
+				if err := limiter.Wait(request.Context()); err != nil {
+					return nil, err
+				}
+			}
+			token, err := c.authorize(request)
+			if err != nil {
+				return nil, err
+			}
+			response, body, err := c.attempt(request)
+			if response != nil {
+				for _, limiter := range limiters {
+					limiter.observe(response)
+				}
+			}
+			if token != nil && !reauthorized && response != nil && response.StatusCode == http.StatusUnauthorized {
+				// The token may have been revoked before its expiry. Retry once with
+				// a new token without counting it as an attempt.
+				c.tokenSource.Invalidate(token)
+				reauthorized, attempt = true, attempt-1
+				if request, err = rewind(request); err != nil {
+					return response, err
+				}
+				continue
+			}
+			if !c.retryPolicy.retry(attempt, request, response, err) {
+				if err != nil {
+					return nil, err
+				}
+				return response, c.handle(v, response, body)
+			}
+
+			if err = sleep(request.Context(), c.retryPolicy.delay(attempt, response)); err != nil {
+				return response, err
+			}
+			if request, err = rewind(request); err != nil {
+				return response, err
+			}
+		}
+	}
+}
+
+// attempt sends a single http request through the client's circuit breaker, if any.
+func (c *Client) attempt(request *http.Request) (*http.Response, []byte, error) {
+	if c.circuitBreaker == nil {
+		return c.do(request)
+	}
+	generation, err := c.circuitBreaker.allow()
+	if err != nil {
+		return nil, nil, err
+	}
+	response, body, err := c.do(request)
+	c.circuitBreaker.record(generation, response, err)
+	return response, body, err
+}
+
+// do sends a single http request and returns the response along with its body.
+func (c *Client) do(request *http.Request) (*http.Response, []byte, error) {
+	if c.signer != nil {
+		if err := c.signer.Sign(request); err != nil {
+			return nil, nil, err
+		}
+	}
 	response, err := c.httpClient.Do(request)
 	if err != nil {
-		return err
+		return nil, nil, &TransportError{Method: request.Method, URL: request.URL.String(), Err: err}
 	}
 	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return err
+		return nil, nil, &TransportError{Method: request.Method, URL: request.URL.String(), Err: err}
 	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	return response, body, nil
+}
 
+// handle decodes the response body into v or into F3Error if the response is not successful.
+func (c *Client) handle(v interface{}, response *http.Response, body []byte) error {
 	switch response.StatusCode {
 	case http.StatusOK, http.StatusCreated:
-		if err = c.unmarshal(body, &v); err != nil {
+		if err := c.unmarshal(body, &v); err != nil {
 			return err
 		}
 		return nil
 	case http.StatusNoContent:
 		return nil
 	default:
-		f3Error := F3Error{StatusCode: response.StatusCode}
-		if err = c.unmarshal(body, &f3Error); err != nil {
-			return err
+		f3Error := F3Error{
+			StatusCode: response.StatusCode,
+			RequestID:  response.Header.Get("X-Request-Id"),
+		}
+		if request := response.Request; request != nil {
+			f3Error.Method, f3Error.URL = request.Method, request.URL.String()
+			if f3Error.RequestID == "" {
+				f3Error.RequestID = request.Header.Get("X-Request-Id")
+			}
+		}
+		if err := c.unmarshal(body, &f3Error); err != nil {
+			// Responses not produced by the API itself (e.g. by a proxy) may not be
+			// JSON-encoded. Keep the status code and the raw body instead.
+			f3Error.ErrorMessage = string(body)
 		}
+		f3Error.FieldErrors = ParseValidationErrors(f3Error.ErrorMessage)
 		return &f3Error
 	}
 }
@@ -119,7 +272,7 @@ func (c *Client) Request(v interface{}, request *http.Request, headers map[strin
 func (c *Client) unmarshal(body []byte, v interface{}) error {
 	if len(body) > 0 {
 		if err := json.Unmarshal(body, v); err != nil {
-			return fmt.Errorf("json %s, error: %v", string(body), err)
+			return &DecodeError{Body: body, Err: err}
 		}
 	}
 	return nil
@@ -129,7 +282,7 @@ func (c *Client) unmarshal(body []byte, v interface{}) error {
 func (c *Client) marshal(v interface{}) ([]byte, error) {
 	body, err := json.Marshal(v)
 	if err != nil {
-		return nil, fmt.Errorf("json %s, error: %v", string(body), err)
+		return nil, fmt.Errorf("json %s, error: %w", string(body), err)
 	}
 	return body, nil
 }
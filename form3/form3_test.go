@@ -1,10 +1,14 @@
 package form3_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"github.com/lmikolajczak/go-form3/form3"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestClient_NewRequest(t *testing.T) {
@@ -69,6 +73,34 @@ func TestClient_Request(t *testing.T) {
 	}
 }
 
+func TestClient_RequestWithContext(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t)
+	defer teardown()
+
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	request, err := f3.NewRequestWithContext(ctx, http.MethodGet, "/slow", nil)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if got := request.Context(); got != ctx {
+		t.Errorf("request context = %v; want: %v", got, ctx)
+	}
+
+	err = f3.Request(nil, request, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v; want: %v", err, context.DeadlineExceeded)
+	}
+}
+
 func testMethod(t *testing.T, r *http.Request, want string) {
 	t.Helper()
 	if got := r.Method; got != want {
@@ -102,3 +134,32 @@ func testHeaders(t *testing.T, r *http.Request, want map[string]string) {
 		}
 	}
 }
+
+func TestWithIDGenerator(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithIDGenerator(func() string { return "ad27e265" }))
+	defer teardown()
+
+	mux.HandleFunc("/v1/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		accountJSON := new(form3.AccountJSON)
+		if err := json.NewDecoder(r.Body).Decode(accountJSON); err != nil {
+			t.Errorf("err = %v; want: nil", err)
+			return
+		}
+		if got := accountJSON.Data.ID; got != "ad27e265" {
+			t.Errorf("id = %s; want: ad27e265", got)
+		}
+		if got := r.Header.Get(form3.IdempotencyKeyHeader); got != "ad27e265" {
+			t.Errorf("idempotency key = %s; want: ad27e265", got)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(accountJSON)
+	})
+
+	account, err := f3.Accounts.Create(context.Background(), "eb0bd6f5", &form3.AccountAttributes{Country: form3.String("GB")})
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if account.ID != "ad27e265" {
+		t.Errorf("id = %s; want: ad27e265", account.ID)
+	}
+}
@@ -0,0 +1,484 @@
+// Package form3test provides an in-process fake of the Form3 account API.
+//
+// The fake implements create, fetch, list, update and delete actions on the account
+// resource with the same validation messages, status codes and version handling as
+// the API, so client code can be tested with go test alone.
+package form3test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lmikolajczak/go-form3/form3"
+	"github.com/lmikolajczak/go-form3/form3/httpsig"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const accountsEndpoint = "/v1/organisation/accounts"
+
+// Hook is called for every request before it is handled by the fake server. If the
+// hook writes a response it must return true, so the request is not handled further.
+type Hook func(w http.ResponseWriter, r *http.Request) bool
+
+// Server is an in-process fake of the Form3 account API.
+type Server struct {
+	// URL is the base URL of the fake server.
+	URL string
+
+	server   *httptest.Server
+	mu       sync.Mutex
+	accounts map[string]*form3.Account
+	order    []string
+	hooks    []Hook
+	requests int
+}
+
+// NewServer starts and returns a new fake server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{accounts: map[string]*form3.Account{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "up"})
+	})
+	mux.HandleFunc(accountsEndpoint, s.handleAccounts)
+	mux.HandleFunc(accountsEndpoint+"/", s.handleAccount)
+	s.server = httptest.NewServer(s.intercept(mux))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the fake server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a new Form3 client configured to talk to the fake server.
+func (s *Server) Client(options ...form3.ClientOption) *form3.Client {
+	return form3.NewClient(s.URL, options...)
+}
+
+// AddHook registers a hook called for every request before it is handled. Hooks are
+// called in the order they were added.
+func (s *Server) AddHook(hook Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, hook)
+}
+
+// FailNext makes the next n requests fail with the given status code and error message.
+func (s *Server) FailNext(n int, statusCode int, message string) {
+	s.AddHook(func(w http.ResponseWriter, r *http.Request) bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if n <= 0 {
+			return false
+		}
+		n--
+		writeError(w, statusCode, message)
+		return true
+	})
+}
+
+// RequireSignatures makes the fake server reject requests without a valid signature
+// with 401 Unauthorized.
+func (s *Server) RequireSignatures(verifier *httpsig.Verifier) {
+	s.AddHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if err := verifier.Verify(r); err != nil {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return true
+		}
+		return false
+	})
+}
+
+// Requests returns the number of requests received by the fake server.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Accounts returns a copy of all stored accounts in the order they were created.
+func (s *Server) Accounts() []form3.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts := make([]form3.Account, 0, len(s.order))
+	for _, id := range s.order {
+		accounts = append(accounts, *clone(s.accounts[id]))
+	}
+	return accounts
+}
+
+// Account returns a copy of the stored account with the given identifier.
+func (s *Server) Account(id string) (*form3.Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[id]
+	if !ok {
+		return nil, false
+	}
+	return clone(account), true
+}
+
+// PutAccount stores the given account, replacing the account with the same identifier.
+func (s *Server) PutAccount(account form3.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[account.ID]; !ok {
+		s.order = append(s.order, account.ID)
+	}
+	if account.Version == nil {
+		account.Version = new(int64)
+	}
+	s.accounts[account.ID] = clone(&account)
+}
+
+// intercept counts the requests and runs the registered hooks before the handler.
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		hooks := append([]Hook(nil), s.hooks...)
+		s.mu.Unlock()
+
+		for _, hook := range hooks {
+			if hook(w, r) {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleAccounts handles the account collection resource.
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.list(w, r)
+	case http.MethodPost:
+		s.create(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleAccount handles a single account resource.
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, accountsEndpoint+"/")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "id is not a valid uuid")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.fetch(w, id)
+	case http.MethodPatch:
+		s.update(w, r, id)
+	case http.MethodDelete:
+		s.delete(w, r, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	accountJSON := new(form3.AccountJSON)
+	if err := json.NewDecoder(r.Body).Decode(accountJSON); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	account := &accountJSON.Data
+	if message := validateAccount(account); message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[account.ID]; ok {
+		writeError(w, http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+		return
+	}
+	now := time.Now().UTC()
+	account.CreatedOn, account.ModifiedOn = &now, &now
+	account.Version = new(int64)
+	s.accounts[account.ID] = clone(account)
+	s.order = append(s.order, account.ID)
+
+	writeJSON(w, http.StatusCreated, accountResponse(account))
+}
+
+func (s *Server) fetch(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, accountResponse(account))
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, id string) {
+	var payload struct {
+		Data struct {
+			Attributes map[string]json.RawMessage `json:"attributes"`
+			Version    *int64                     `json:"version"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if payload.Data.Version == nil {
+		writeError(w, http.StatusBadRequest, validationError(2, []string{"version in body is required"}))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+	if *stored.Version != *payload.Data.Version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	updated, err := patch(stored, payload.Data.Attributes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if failures := validateAttributes(updated.Attributes); len(failures) > 0 {
+		writeError(w, http.StatusBadRequest, validationError(3, failures))
+		return
+	}
+	now := time.Now().UTC()
+	version := *stored.Version + 1
+	updated.ModifiedOn, updated.Version = &now, &version
+	s.accounts[id] = updated
+
+	writeJSON(w, http.StatusOK, accountResponse(updated))
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, id string) {
+	version, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid version number")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if *account.Version != version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+	delete(s.accounts, id)
+	for i, stored := range s.order {
+		if stored == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	number, size := 0, 100
+	if v := query.Get("page[number]"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid page number")
+			return
+		}
+		number = n
+	}
+	if v := query.Get("page[size]"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid page size")
+			return
+		}
+		size = n
+	}
+
+	s.mu.Lock()
+	var matching []form3.Account
+	for _, id := range s.order {
+		if account := s.accounts[id]; matches(account, query) {
+			matching = append(matching, *clone(account))
+		}
+	}
+	s.mu.Unlock()
+
+	last := 0
+	if len(matching) > 0 {
+		last = (len(matching) - 1) / size
+	}
+	page := []form3.Account{}
+	if start := number * size; start < len(matching) {
+		end := start + size
+		if end > len(matching) {
+			end = len(matching)
+		}
+		page = matching[start:end]
+	}
+
+	links := &form3.Links{
+		First: pageLink(query, 0, size),
+		Last:  pageLink(query, last, size),
+		Self:  pageLink(query, number, size),
+	}
+	if number < last {
+		links.Next = pageLink(query, number+1, size)
+	}
+	if number > 0 {
+		links.Prev = pageLink(query, number-1, size)
+	}
+	writeJSON(w, http.StatusOK, form3.AccountListJSON{Data: page, Links: links})
+}
+
+// matches reports whether the account matches the filters of the query. The customer
+// identifier is not modelled by form3.AccountAttributes, so it is taken from Extra.
+func matches(account *form3.Account, query url.Values) bool {
+	attributes := account.Attributes
+	if attributes == nil {
+		attributes = new(form3.AccountAttributes)
+	}
+	country, customerID := "", ""
+	if attributes.Country != nil {
+		country = *attributes.Country
+	}
+	if raw, ok := attributes.Extra["customer_id"]; ok {
+		json.Unmarshal(raw, &customerID)
+	}
+	filters := map[string]string{
+		"filter[account_number]": attributes.AccountNumber,
+		"filter[bank_id]":        attributes.BankID,
+		"filter[bank_id_code]":   attributes.BankIDCode,
+		"filter[country]":        country,
+		"filter[customer_id]":    customerID,
+		"filter[iban]":           attributes.Iban,
+	}
+	for key, value := range filters {
+		if want := query.Get(key); want != "" && want != value {
+			return false
+		}
+	}
+	return true
+}
+
+// pageLink returns the link to the page with the given number keeping the filters
+// of the query.
+func pageLink(query url.Values, number, size int) string {
+	values := url.Values{}
+	for key, value := range query {
+		if strings.HasPrefix(key, "filter[") {
+			values[key] = value
+		}
+	}
+	values.Set("page[number]", strconv.Itoa(number))
+	values.Set("page[size]", strconv.Itoa(size))
+	return accountsEndpoint + "?" + values.Encode()
+}
+
+// patch returns a copy of the account with the given attributes modified. Attributes
+// set to null are removed.
+func patch(account *form3.Account, attributes map[string]json.RawMessage) (*form3.Account, error) {
+	data, err := json.Marshal(account.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	var current map[string]json.RawMessage
+	if err = json.Unmarshal(data, &current); err != nil {
+		return nil, err
+	}
+	if current == nil {
+		// Accounts stored with PutAccount may have no attributes.
+		current = map[string]json.RawMessage{}
+	}
+	for key, value := range attributes {
+		if string(value) == "null" {
+			delete(current, key)
+		} else {
+			current[key] = value
+		}
+	}
+	if data, err = json.Marshal(current); err != nil {
+		return nil, err
+	}
+
+	updated := clone(account)
+	updated.Attributes = new(form3.AccountAttributes)
+	if err = json.Unmarshal(data, updated.Attributes); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// accountResponse returns the response payload of a single account.
+func accountResponse(account *form3.Account) interface{} {
+	return struct {
+		Data  *form3.Account `json:"data"`
+		Links *form3.Links   `json:"links"`
+	}{
+		Data:  account,
+		Links: &form3.Links{Self: accountsEndpoint + "/" + account.ID},
+	}
+}
+
+// clone returns a deep copy of the account.
+func clone(account *form3.Account) *form3.Account {
+	data, err := json.Marshal(account)
+	if err != nil {
+		panic(fmt.Sprintf("form3test: cannot marshal account: %v", err))
+	}
+	copied := new(form3.Account)
+	if err = json.Unmarshal(data, copied); err != nil {
+		panic(fmt.Sprintf("form3test: cannot unmarshal account: %v", err))
+	}
+	return copied
+}
+
+// writeJSON writes the JSON encoding of v with the given status code.
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the Form3 API error with the given status code and message.
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, form3.F3Error{ErrorMessage: message})
+}
+
+// TestClient returns a client talking to the Form3 account API at FORM3_API_BASE_URL
+// or, if the variable is not set, to a new fake server.
+func TestClient(t *testing.T, options ...form3.ClientOption) (*form3.Client, func()) {
+	t.Helper()
+	if baseURL := os.Getenv("FORM3_API_BASE_URL"); len(baseURL) > 0 {
+		return form3.NewClient(baseURL, options...), func() {}
+	}
+	server := NewServer()
+	return server.Client(options...), server.Close
+}
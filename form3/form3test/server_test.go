@@ -0,0 +1,198 @@
+package form3test_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/lmikolajczak/go-form3/form3"
+	"github.com/lmikolajczak/go-form3/form3/form3test"
+	"github.com/lmikolajczak/go-form3/form3/httpsig"
+	"net/http"
+	"testing"
+)
+
+func TestServer_FailNext(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	server.FailNext(1, http.StatusServiceUnavailable, "unavailable")
+
+	_, err := f3.Accounts.Fetch(context.Background(), uuid.NewString())
+	var f3Error *form3.F3Error
+	if !errors.As(err, &f3Error) || f3Error.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("err = %v; want: http 503", err)
+	}
+
+	_, err = f3.Accounts.Fetch(context.Background(), uuid.NewString())
+	if !errors.As(err, &f3Error) || f3Error.StatusCode != http.StatusNotFound {
+		t.Errorf("err = %v; want: http 404", err)
+	}
+	if got := server.Requests(); got != 2 {
+		t.Errorf("requests = %d; want: 2", got)
+	}
+}
+
+func TestServer_Update(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	id := uuid.NewString()
+	server.PutAccount(form3.Account{
+		ID:             id,
+		OrganisationID: uuid.NewString(),
+		Type:           "accounts",
+		Attributes: &form3.AccountAttributes{
+			Country:                 form3.String("GB"),
+			Name:                    []string{"Old Name"},
+			SecondaryIdentification: "Secondary",
+		},
+	})
+
+	patch := &form3.AccountAttributesPatch{
+		Name:                    form3.Set([]string{"New Name"}),
+		SecondaryIdentification: form3.Null[string](),
+	}
+	if _, err := f3.Accounts.Update(context.Background(), id, 0, patch); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+
+	account, _ := server.Account(id)
+	if got := *account.Version; got != 1 {
+		t.Errorf("version = %d; want: 1", got)
+	}
+	if got := account.Attributes.Name[0]; got != "New Name" {
+		t.Errorf("name = %s; want: New Name", got)
+	}
+	if got := account.Attributes.SecondaryIdentification; got != "" {
+		t.Errorf("secondary identification = %s; want: empty", got)
+	}
+
+	_, err := f3.Accounts.Update(context.Background(), id, 0, patch)
+	var f3Error *form3.F3Error
+	if !errors.As(err, &f3Error) || f3Error.StatusCode != http.StatusConflict {
+		t.Errorf("err = %v; want: http 409", err)
+	}
+}
+
+func TestServer_Iterate(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	for i := 0; i < 5; i++ {
+		server.PutAccount(form3.Account{
+			ID:         uuid.NewString(),
+			Attributes: &form3.AccountAttributes{Country: form3.String("GB")},
+		})
+	}
+
+	ctx := context.Background()
+	it := f3.Accounts.Iterate(ctx, &form3.ListAccountsOptions{PageSize: 2})
+	var ids []string
+	for it.Next(ctx) {
+		ids = append(ids, it.Account().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+
+	for i, account := range server.Accounts() {
+		if i >= len(ids) || ids[i] != account.ID {
+			t.Errorf("accounts = %v; want: account %s at %d", ids, account.ID, i)
+		}
+	}
+}
+
+func TestServer_ListFilter(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	for _, customerID := range []string{`"customer-1"`, `"customer-2"`} {
+		server.PutAccount(form3.Account{
+			ID: uuid.NewString(),
+			Attributes: &form3.AccountAttributes{
+				Country: form3.String("GB"),
+				Extra:   map[string]json.RawMessage{"customer_id": json.RawMessage(customerID)},
+			},
+		})
+	}
+
+	list, err := f3.Accounts.List(context.Background(), &form3.ListAccountsOptions{
+		Filter: form3.AccountFilter{CustomerID: "customer-2"},
+	})
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if len(list.Data) != 1 || string(list.Data[0].Attributes.Extra["customer_id"]) != `"customer-2"` {
+		t.Errorf("accounts = %v; want: account of customer-2", list.Data)
+	}
+}
+
+func TestServer_NoAttributes(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	id := uuid.NewString()
+	server.PutAccount(form3.Account{ID: id})
+
+	list, err := f3.Accounts.List(context.Background(), &form3.ListAccountsOptions{
+		Filter: form3.AccountFilter{Country: "GB"},
+	})
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if len(list.Data) != 0 {
+		t.Errorf("accounts = %v; want: none", list.Data)
+	}
+
+	patch := &form3.AccountAttributesPatch{Country: form3.Set("GB"), Name: form3.Set([]string{"Name"})}
+	if _, err = f3.Accounts.Update(context.Background(), id, 0, patch); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	account, _ := server.Account(id)
+	if got := account.Attributes.Name; len(got) != 1 || got[0] != "Name" {
+		t.Errorf("name = %v; want: [Name]", got)
+	}
+}
+
+func TestServer_RequireSignatures(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	signer, err := httpsig.NewSigner("key-id", key)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	verifier, err := httpsig.NewVerifier("key-id", key.Public())
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+
+	server := form3test.NewServer()
+	defer server.Close()
+	server.RequireSignatures(verifier)
+
+	attributes := &form3.AccountAttributes{Country: form3.String("GB"), Name: []string{"Samantha Holder"}}
+	_, err = server.Client().Accounts.Create(context.Background(), uuid.NewString(), attributes)
+	if !errors.Is(err, form3.ErrUnauthorized) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrUnauthorized)
+	}
+
+	f3 := server.Client(form3.WithSigner(signer))
+	account, err := f3.Accounts.Create(context.Background(), uuid.NewString(), attributes)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if _, err = f3.Accounts.Fetch(context.Background(), account.ID); err != nil {
+		t.Errorf("err = %v; want: nil", err)
+	}
+}
@@ -0,0 +1,59 @@
+package form3test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lmikolajczak/go-form3/form3"
+	"strings"
+)
+
+// validationError returns the message of the validation failure reported by the API
+// for the given failures nested under the given number of levels.
+func validationError(depth int, failures []string) string {
+	return strings.Repeat("validation failure list:\n", depth) + strings.Join(failures, "\n")
+}
+
+// validateAccount returns the validation error message for the given account or an
+// empty string if the account is valid.
+func validateAccount(account *form3.Account) string {
+	var failures []string
+	if account.ID == "" {
+		failures = append(failures, "id in body is required")
+	} else if _, err := uuid.Parse(account.ID); err != nil {
+		failures = append(failures, fmt.Sprintf("id in body must be of type uuid: %q", account.ID))
+	}
+	if account.OrganisationID == "" {
+		failures = append(failures, "organisation_id in body is required")
+	} else if _, err := uuid.Parse(account.OrganisationID); err != nil {
+		failures = append(failures, fmt.Sprintf("organisation_id in body must be of type uuid: %q", account.OrganisationID))
+	}
+	if account.Type != "accounts" {
+		failures = append(failures, "type in body should be one of [accounts]")
+	}
+	if account.Attributes == nil {
+		failures = append(failures, "attributes in body is required")
+	}
+	if len(failures) > 0 {
+		return validationError(2, failures)
+	}
+
+	if failures = validateAttributes(account.Attributes); len(failures) > 0 {
+		return validationError(3, failures)
+	}
+	return ""
+}
+
+// validateAttributes returns validation failures of the given account attributes.
+// The constraints of the JSON schema of the API are shared with the client.
+func validateAttributes(attributes *form3.AccountAttributes) []string {
+	var validationErr *form3.ValidationError
+	if !errors.As(attributes.ValidateSchema(), &validationErr) {
+		return nil
+	}
+	failures := make([]string, 0, len(validationErr.FieldErrors))
+	for _, fieldError := range validationErr.FieldErrors {
+		failures = append(failures, fieldError.Error())
+	}
+	return failures
+}
@@ -0,0 +1,289 @@
+// Package httpsig implements signing and verification of http requests with HTTP
+// message signatures as required by the Form3 REST API, i.e. the Authorization header
+//
+//	Signature keyId="...",algorithm="rsa-sha256",headers="(request-target) host date digest content-length",signature="..."
+//
+// along with the Date and Digest headers of the request.
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned when a request cannot be signed or its signature is invalid.
+var (
+	ErrUnsupportedKey   = errors.New("httpsig: unsupported key")
+	ErrMissingSignature = errors.New("httpsig: missing signature")
+	ErrInvalidSignature = errors.New("httpsig: invalid signature")
+	ErrUnknownKey       = errors.New("httpsig: unknown key")
+	ErrDigestMismatch   = errors.New("httpsig: digest does not match the body")
+	ErrExpired          = errors.New("httpsig: date is outside of the allowed clock skew")
+)
+
+// Algorithms of the signatures.
+const (
+	AlgorithmRSASHA256   = "rsa-sha256"
+	AlgorithmECDSASHA256 = "ecdsa-sha256"
+)
+
+// DefaultHeaders are the headers covered by the signature, as required by Form3.
+var DefaultHeaders = []string{"(request-target)", "host", "date", "digest", "content-length"}
+
+// DefaultMaxSkew is the maximum difference between the Date header of a request and
+// the current time accepted by a Verifier.
+const DefaultMaxSkew = 5 * time.Minute
+
+// Signer signs http requests with a private key.
+type Signer struct {
+	keyID     string
+	key       crypto.Signer
+	algorithm string
+	now       func() time.Time
+}
+
+// NewSigner returns a Signer signing requests with the given RSA or ECDSA private key
+// identified by keyID.
+func NewSigner(keyID string, key crypto.Signer) (*Signer, error) {
+	algorithm, err := algorithmOf(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{keyID: keyID, key: key, algorithm: algorithm, now: time.Now}, nil
+}
+
+// NewSignerFromPEM returns a Signer signing requests with the PEM encoded RSA or ECDSA
+// private key identified by keyID.
+func NewSignerFromPEM(keyID string, data []byte) (*Signer, error) {
+	key, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigner(keyID, key)
+}
+
+// Sign sets the Date, Digest and Authorization headers of the request. The body of
+// the request is read with GetBody, if set, or buffered so it can still be sent.
+func (s *Signer) Sign(request *http.Request) error {
+	body, err := readBody(request)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Date", s.now().UTC().Format(http.TimeFormat))
+	request.Header.Set("Digest", digest(body))
+
+	hashed := sha256.Sum256([]byte(signingString(request, DefaultHeaders)))
+	signature, err := s.key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("httpsig: sign: %w", err)
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		`Signature keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.keyID, s.algorithm, strings.Join(DefaultHeaders, " "), base64.StdEncoding.EncodeToString(signature),
+	))
+	return nil
+}
+
+// Verifier verifies signatures of http requests with public keys.
+type Verifier struct {
+	keys    map[string]crypto.PublicKey
+	maxSkew time.Duration
+	now     func() time.Time
+}
+
+// NewVerifier returns a Verifier accepting signatures made with the private key
+// corresponding to the given RSA or ECDSA public key identified by keyID.
+func NewVerifier(keyID string, key crypto.PublicKey) (*Verifier, error) {
+	v := &Verifier{keys: map[string]crypto.PublicKey{}, maxSkew: DefaultMaxSkew, now: time.Now}
+	if err := v.AddKey(keyID, key); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// AddKey makes the verifier accept signatures made with another key.
+func (v *Verifier) AddKey(keyID string, key crypto.PublicKey) error {
+	if _, err := algorithmOf(key); err != nil {
+		return err
+	}
+	v.keys[keyID] = key
+	return nil
+}
+
+// SetMaxSkew sets the maximum difference between the Date header of a request and
+// the current time. Zero disables the check.
+func (v *Verifier) SetMaxSkew(maxSkew time.Duration) {
+	v.maxSkew = maxSkew
+}
+
+// Verify checks the signature in the Authorization header of the request, the Digest
+// of its body and its Date. The body of the request can still be read afterwards.
+func (v *Verifier) Verify(request *http.Request) error {
+	params, err := parseAuthorization(request.Header.Get("Authorization"))
+	if err != nil {
+		return err
+	}
+	key, ok := v.keys[params["keyId"]]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, params["keyId"])
+	}
+	algorithm, _ := algorithmOf(key)
+	if params["algorithm"] != algorithm {
+		return fmt.Errorf("%w: unexpected algorithm %s", ErrInvalidSignature, params["algorithm"])
+	}
+	headers := strings.Fields(params["headers"])
+	for _, header := range DefaultHeaders {
+		if !contains(headers, header) {
+			return fmt.Errorf("%w: header %s is not signed", ErrInvalidSignature, header)
+		}
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	hashed := sha256.Sum256([]byte(signingString(request, headers)))
+	if !verify(key, hashed[:], signature) {
+		return ErrInvalidSignature
+	}
+
+	body, err := readBody(request)
+	if err != nil {
+		return err
+	}
+	if request.Header.Get("Digest") != digest(body) {
+		return ErrDigestMismatch
+	}
+
+	if v.maxSkew > 0 {
+		date, err := http.ParseTime(request.Header.Get("Date"))
+		if err != nil {
+			return fmt.Errorf("%w: invalid date: %v", ErrInvalidSignature, err)
+		}
+		if skew := v.now().Sub(date); skew > v.maxSkew || skew < -v.maxSkew {
+			return ErrExpired
+		}
+	}
+	return nil
+}
+
+// signingString returns the string signed for the given headers of the request.
+func signingString(request *http.Request, headers []string) string {
+	lines := make([]string, 0, len(headers))
+	for _, header := range headers {
+		var value string
+		switch header {
+		case "(request-target)":
+			value = strings.ToLower(request.Method) + " " + request.URL.RequestURI()
+		case "host":
+			value = request.Host
+			if value == "" {
+				value = request.URL.Host
+			}
+		case "content-length":
+			value = strconv.FormatInt(request.ContentLength, 10)
+		default:
+			value = request.Header.Get(header)
+		}
+		lines = append(lines, header+": "+value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseAuthorization returns the parameters of the Signature authorization header.
+func parseAuthorization(header string) (map[string]string, error) {
+	credentials, ok := strings.CutPrefix(header, "Signature ")
+	if !ok {
+		return nil, ErrMissingSignature
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(credentials, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed parameter %s", ErrInvalidSignature, param)
+		}
+		params[name] = strings.Trim(value, `"`)
+	}
+	for _, name := range []string{"keyId", "algorithm", "headers", "signature"} {
+		if params[name] == "" {
+			return nil, fmt.Errorf("%w: missing parameter %s", ErrInvalidSignature, name)
+		}
+	}
+	return params, nil
+}
+
+// readBody returns the body of the request and makes sure it can be read again.
+func readBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("httpsig: read body: %w", err)
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("httpsig: read body: %w", err)
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// digest returns the value of the Digest header of the body.
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// algorithmOf returns the signature algorithm of the public key.
+func algorithmOf(key crypto.PublicKey) (string, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRSASHA256, nil
+	case *ecdsa.PublicKey:
+		return AlgorithmECDSASHA256, nil
+	default:
+		return "", fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+}
+
+// verify reports whether the signature of the hashed signing string is valid.
+func verify(key crypto.PublicKey, hashed, signature []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed, signature) == nil
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, hashed, signature)
+	default:
+		return false
+	}
+}
+
+// contains reports whether the value is present in the values.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
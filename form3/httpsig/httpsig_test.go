@@ -0,0 +1,174 @@
+package httpsig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/lmikolajczak/go-form3/form3/httpsig"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// testKeyPEM returns a new PEM encoded private key and the PEM encoded public key.
+func testKeyPEM(t *testing.T, algorithm string) ([]byte, []byte) {
+	t.Helper()
+
+	var (
+		key       interface{}
+		publicKey interface{}
+		err       error
+	)
+	switch algorithm {
+	case httpsig.AlgorithmRSASHA256:
+		var rsaKey *rsa.PrivateKey
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		key, publicKey = rsaKey, rsaKey.Public()
+	case httpsig.AlgorithmECDSASHA256:
+		var ecdsaKey *ecdsa.PrivateKey
+		ecdsaKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		key, publicKey = ecdsaKey, ecdsaKey.Public()
+	}
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
+
+func testSignerVerifier(t *testing.T, algorithm string) (*httpsig.Signer, *httpsig.Verifier) {
+	t.Helper()
+
+	privatePEM, publicPEM := testKeyPEM(t, algorithm)
+	signer, err := httpsig.NewSignerFromPEM("key-id", privatePEM)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	publicKey, err := httpsig.ParsePublicKeyPEM(publicPEM)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	verifier, err := httpsig.NewVerifier("key-id", publicKey)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	return signer, verifier
+}
+
+func TestSigner_Sign(t *testing.T) {
+	for _, algorithm := range []string{httpsig.AlgorithmRSASHA256, httpsig.AlgorithmECDSASHA256} {
+		t.Run(algorithm, func(t *testing.T) {
+			signer, verifier := testSignerVerifier(t, algorithm)
+
+			request, err := http.NewRequest(http.MethodPost, "https://api.form3.tech/v1/organisation/accounts?x=1", strings.NewReader(`{"data":{}}`))
+			if err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+			if err = signer.Sign(request); err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+
+			authorization := request.Header.Get("Authorization")
+			want := `Signature keyId="key-id",algorithm="` + algorithm + `",headers="(request-target) host date digest content-length",signature=`
+			if !strings.HasPrefix(authorization, want) {
+				t.Errorf("authorization = %s; want prefix: %s", authorization, want)
+			}
+			if got, want := request.Header.Get("Digest"), "SHA-256=f7nRZtGhW84LnwhfOBiUb9kpfkUTpKA0oM63SSkrTA0="; got != want {
+				t.Errorf("digest = %s; want: %s", got, want)
+			}
+			if err = verifier.Verify(request); err != nil {
+				t.Errorf("err = %v; want: nil", err)
+			}
+			if body, _ := io.ReadAll(request.Body); string(body) != `{"data":{}}` {
+				t.Errorf("body = %s; want: %s", body, `{"data":{}}`)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	signer, verifier := testSignerVerifier(t, httpsig.AlgorithmECDSASHA256)
+	_, otherVerifier := testSignerVerifier(t, httpsig.AlgorithmECDSASHA256)
+
+	testcases := []struct {
+		name     string
+		modify   func(request *http.Request)
+		verifier *httpsig.Verifier
+		wantErr  error
+	}{
+		{
+			name:     "unsigned",
+			modify:   func(request *http.Request) { request.Header.Del("Authorization") },
+			verifier: verifier,
+			wantErr:  httpsig.ErrMissingSignature,
+		},
+		{
+			name:     "modified target",
+			modify:   func(request *http.Request) { request.URL.Path = "/v1/organisation/other" },
+			verifier: verifier,
+			wantErr:  httpsig.ErrInvalidSignature,
+		},
+		{
+			name: "modified body",
+			modify: func(request *http.Request) {
+				request.Body, request.GetBody = io.NopCloser(strings.NewReader(`{"data":[]}`)), nil
+			},
+			verifier: verifier,
+			wantErr:  httpsig.ErrDigestMismatch,
+		},
+		{
+			name:     "modified date",
+			modify:   func(request *http.Request) { request.Header.Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT") },
+			verifier: verifier,
+			wantErr:  httpsig.ErrInvalidSignature,
+		},
+		{
+			name:     "other key",
+			modify:   func(request *http.Request) {},
+			verifier: otherVerifier,
+			wantErr:  httpsig.ErrInvalidSignature,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodPost, "https://api.form3.tech/v1/organisation/accounts", strings.NewReader(`{"data":{}}`))
+			if err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+			if err = signer.Sign(request); err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+
+			tc.modify(request)
+			if err = tc.verifier.Verify(request); !errors.Is(err, tc.wantErr) {
+				t.Errorf("err = %v; want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	_, publicPEM := testKeyPEM(t, httpsig.AlgorithmECDSASHA256)
+
+	if _, err := httpsig.ParsePrivateKeyPEM(publicPEM); !errors.Is(err, httpsig.ErrInvalidPEM) {
+		t.Errorf("err = %v; want: %v", err, httpsig.ErrInvalidPEM)
+	}
+	if _, err := httpsig.ParsePrivateKeyPEM([]byte("not a key")); !errors.Is(err, httpsig.ErrInvalidPEM) {
+		t.Errorf("err = %v; want: %v", err, httpsig.ErrInvalidPEM)
+	}
+}
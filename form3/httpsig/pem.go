@@ -0,0 +1,83 @@
+package httpsig
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPEM is returned when PEM encoded data does not contain a supported key.
+var ErrInvalidPEM = errors.New("httpsig: invalid PEM")
+
+// ParsePrivateKeyPEM parses a PEM encoded RSA or ECDSA private key in PKCS #1, SEC 1
+// or PKCS #8 form.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	var (
+		key interface{}
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: unexpected block %s", ErrInvalidPEM, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPEM, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+	if _, err = algorithmOf(signer.Public()); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// ParsePublicKeyPEM parses a PEM encoded RSA or ECDSA public key in PKIX or PKCS #1
+// form, or the public key of a PEM encoded certificate.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	var (
+		key interface{}
+		err error
+	)
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var certificate *x509.Certificate
+		if certificate, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = certificate.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("%w: unexpected block %s", ErrInvalidPEM, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPEM, err)
+	}
+
+	if _, err = algorithmOf(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,99 @@
+package iban
+
+import (
+	"strconv"
+	"strings"
+)
+
+// span represents the position of a component within the BBAN.
+type span struct {
+	start, end int
+}
+
+// of returns the component of the BBAN.
+func (s span) of(bban string) string {
+	if s.end == 0 || s.end > len(bban) {
+		return ""
+	}
+	return bban[s.start:s.end]
+}
+
+// spec represents the IBAN structure of a single country.
+type spec struct {
+	// length is the length of the IBAN in electronic format.
+	length int
+	// structure is the BBAN structure in the SWIFT notation, e.g. "4!a6!n8!n",
+	// where n stands for digits, a for upper case letters and c for alphanumerics.
+	structure string
+	bank      span
+	branch    span
+	account   span
+	// checkDigits reports whether the BBAN contains national check digits, e.g. the
+	// French RIB key.
+	checkDigits bool
+}
+
+// matches reports whether the BBAN matches the structure.
+func (s spec) matches(bban string) bool {
+	structure := s.structure
+	for structure != "" {
+		i := strings.IndexByte(structure, '!')
+		if i < 0 || i+1 >= len(structure) {
+			return false
+		}
+		n, err := strconv.Atoi(structure[:i])
+		if err != nil || n > len(bban) {
+			return false
+		}
+		for _, r := range bban[:n] {
+			if !allowed(structure[i+1], r) {
+				return false
+			}
+		}
+		bban, structure = bban[n:], structure[i+2:]
+	}
+	return bban == ""
+}
+
+// allowed reports whether the character is allowed by the character class of the
+// SWIFT notation.
+func allowed(class byte, r rune) bool {
+	digit, letter := r >= '0' && r <= '9', r >= 'A' && r <= 'Z'
+	switch class {
+	case 'n':
+		return digit
+	case 'a':
+		return letter
+	case 'c':
+		return digit || letter
+	}
+	return false
+}
+
+// specs lists IBAN structures of the supported countries.
+var specs = map[string]spec{
+	"AT": {length: 20, structure: "5!n11!n", bank: span{0, 5}, account: span{5, 16}},
+	"BE": {length: 16, structure: "3!n7!n2!n", bank: span{0, 3}, account: span{3, 10}, checkDigits: true},
+	"CH": {length: 21, structure: "5!n12!c", bank: span{0, 5}, account: span{5, 17}},
+	"DE": {length: 22, structure: "8!n10!n", bank: span{0, 8}, account: span{8, 18}},
+	"DK": {length: 18, structure: "4!n9!n1!n", bank: span{0, 4}, account: span{4, 14}, checkDigits: true},
+	"ES": {length: 24, structure: "4!n4!n1!n1!n10!n", bank: span{0, 4}, branch: span{4, 8}, account: span{10, 20}, checkDigits: true},
+	"FI": {length: 18, structure: "3!n11!n", bank: span{0, 3}, account: span{3, 14}, checkDigits: true},
+	"FR": {length: 27, structure: "5!n5!n11!c2!n", bank: span{0, 5}, branch: span{5, 10}, account: span{10, 21}, checkDigits: true},
+	"GB": {length: 22, structure: "4!a6!n8!n", bank: span{0, 4}, branch: span{4, 10}, account: span{10, 18}},
+	"GR": {length: 27, structure: "3!n4!n16!c", bank: span{0, 3}, branch: span{3, 7}, account: span{7, 23}},
+	"IE": {length: 22, structure: "4!a6!n8!n", bank: span{0, 4}, branch: span{4, 10}, account: span{10, 18}},
+	"IT": {length: 27, structure: "1!a5!n5!n12!c", bank: span{1, 6}, branch: span{6, 11}, account: span{11, 23}, checkDigits: true},
+	"LU": {length: 20, structure: "3!n13!c", bank: span{0, 3}, account: span{3, 16}},
+	"NL": {length: 18, structure: "4!a10!n", bank: span{0, 4}, account: span{4, 14}},
+	"NO": {length: 15, structure: "4!n6!n1!n", bank: span{0, 4}, account: span{4, 11}, checkDigits: true},
+	"PL": {length: 28, structure: "8!n16!n", bank: span{0, 8}, account: span{8, 24}},
+	"PT": {length: 25, structure: "4!n4!n11!n2!n", bank: span{0, 4}, branch: span{4, 8}, account: span{8, 19}, checkDigits: true},
+	"SE": {length: 24, structure: "3!n16!n1!n", bank: span{0, 3}, account: span{3, 20}, checkDigits: true},
+}
+
+// Supported reports whether IBAN structure of the country is known.
+func Supported(country string) bool {
+	_, ok := specs[country]
+	return ok
+}
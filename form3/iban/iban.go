@@ -0,0 +1,152 @@
+// Package iban implements parsing, validation, formatting and construction of
+// International Bank Account Numbers (ISO 13616).
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Errors returned when an IBAN is invalid.
+var (
+	ErrUnsupportedCountry = errors.New("iban: unsupported country")
+	ErrInvalidLength      = errors.New("iban: invalid length")
+	ErrInvalidFormat      = errors.New("iban: invalid format")
+	ErrInvalidChecksum    = errors.New("iban: invalid checksum")
+)
+
+// ErrNationalCheckDigits is returned by New for countries whose BBAN contains
+// national check digits, which New does not compute.
+var ErrNationalCheckDigits = errors.New("iban: national check digits are not supported")
+
+// IBAN represents a parsed International Bank Account Number.
+type IBAN struct {
+	CountryCode string
+	CheckDigits string
+	BBAN        string
+}
+
+// Parse parses and validates the IBAN given in electronic or print format.
+func Parse(s string) (*IBAN, error) {
+	s = Normalize(s)
+	if len(s) < 4 {
+		return nil, ErrInvalidLength
+	}
+
+	country := s[:2]
+	spec, ok := specs[country]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCountry, country)
+	}
+	if len(s) != spec.length {
+		return nil, fmt.Errorf("%w: %d characters, %s IBAN has %d", ErrInvalidLength, len(s), country, spec.length)
+	}
+	if !isDigits(s[2:4]) || !spec.matches(s[4:]) {
+		return nil, fmt.Errorf("%w: %s BBAN should match %s", ErrInvalidFormat, country, spec.structure)
+	}
+	if mod97(s[4:]+s[:4]) != 1 {
+		return nil, ErrInvalidChecksum
+	}
+	return &IBAN{CountryCode: country, CheckDigits: s[2:4], BBAN: s[4:]}, nil
+}
+
+// Validate reports whether the IBAN given in electronic or print format is valid.
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+// New returns an IBAN constructed from the country code, bank identifier and account
+// number. The bank identifier forms the beginning of the BBAN (bank and, where
+// applicable, branch code) and the account number the rest of it. The account
+// number is left-padded with zeros to fill the BBAN.
+//
+// Countries whose BBAN contains national check digits (BE, DK, ES, FI, FR, IT, NO,
+// PT and SE) are rejected with ErrNationalCheckDigits, as zero-padding would produce
+// an IBAN with a valid checksum that does not identify a real account.
+func New(country, bankID, accountNumber string) (*IBAN, error) {
+	country = strings.ToUpper(country)
+	spec, ok := specs[country]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCountry, country)
+	}
+	if spec.checkDigits {
+		return nil, fmt.Errorf("%w: %s", ErrNationalCheckDigits, country)
+	}
+
+	bankID, accountNumber = Normalize(bankID), Normalize(accountNumber)
+	padding := spec.length - 4 - len(bankID) - len(accountNumber)
+	if padding < 0 {
+		return nil, fmt.Errorf("%w: %s BBAN has %d characters", ErrInvalidLength, country, spec.length-4)
+	}
+	bban := bankID + strings.Repeat("0", padding) + accountNumber
+	if !spec.matches(bban) {
+		return nil, fmt.Errorf("%w: %s BBAN should match %s", ErrInvalidFormat, country, spec.structure)
+	}
+	return &IBAN{CountryCode: country, CheckDigits: CheckDigits(country, bban), BBAN: bban}, nil
+}
+
+// CheckDigits returns the check digits of the IBAN with the given country code and BBAN.
+func CheckDigits(country, bban string) string {
+	return fmt.Sprintf("%02d", 98-mod97(strings.ToUpper(bban+country)+"00"))
+}
+
+// Normalize removes spaces from s and converts it to upper case.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// String returns the IBAN in electronic format, e.g. GB29NWBK60161331926819.
+func (i *IBAN) String() string {
+	return i.CountryCode + i.CheckDigits + i.BBAN
+}
+
+// Print returns the IBAN in print format, e.g. GB29 NWBK 6016 1331 9268 19.
+func (i *IBAN) Print() string {
+	s := i.String()
+	groups := make([]string, 0, len(s)/4+1)
+	for len(s) > 4 {
+		groups = append(groups, s[:4])
+		s = s[4:]
+	}
+	return strings.Join(append(groups, s), " ")
+}
+
+// BankCode returns the bank identifier part of the BBAN.
+func (i *IBAN) BankCode() string {
+	return specs[i.CountryCode].bank.of(i.BBAN)
+}
+
+// BranchCode returns the branch identifier part of the BBAN or an empty string if
+// the country does not use branch codes.
+func (i *IBAN) BranchCode() string {
+	return specs[i.CountryCode].branch.of(i.BBAN)
+}
+
+// AccountNumber returns the account number part of the BBAN.
+func (i *IBAN) AccountNumber() string {
+	return specs[i.CountryCode].account.of(i.BBAN)
+}
+
+// mod97 returns the remainder of the division by 97 of the number created by
+// replacing letters of s with two digit numbers (A = 10, ..., Z = 35).
+func mod97(s string) int {
+	remainder := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		}
+	}
+	return remainder
+}
+
+// isDigits reports whether s consists of digits only.
+func isDigits(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
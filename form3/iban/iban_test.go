@@ -0,0 +1,98 @@
+package iban_test
+
+import (
+	"errors"
+	"github.com/lmikolajczak/go-form3/form3/iban"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testcases := []struct {
+		input       string
+		wantErr     error
+		wantBank    string
+		wantBranch  string
+		wantAccount string
+	}{
+		{input: "GB29NWBK60161331926819", wantBank: "NWBK", wantBranch: "601613", wantAccount: "31926819"},
+		{input: "gb29 nwbk 6016 1331 9268 19", wantBank: "NWBK", wantBranch: "601613", wantAccount: "31926819"},
+		{input: "DE89370400440532013000", wantBank: "37040044", wantAccount: "0532013000"},
+		{input: "NL91ABNA0417164300", wantBank: "ABNA", wantAccount: "0417164300"},
+		{input: "FR1420041010050500013M02606", wantBank: "20041", wantBranch: "01005", wantAccount: "0500013M026"},
+		{input: "ES9121000418450200051332", wantBank: "2100", wantBranch: "0418", wantAccount: "0200051332"},
+		{input: "IT60X0542811101000000123456", wantBank: "05428", wantBranch: "11101", wantAccount: "000000123456"},
+		{input: "BE68539007547034", wantBank: "539", wantAccount: "0075470"},
+		{input: "CH9300762011623852957", wantBank: "00762", wantAccount: "011623852957"},
+		{input: "PL61109010140000071219812874", wantBank: "10901014", wantAccount: "0000071219812874"},
+		{input: "GB28NWBK60161331926819", wantErr: iban.ErrInvalidChecksum},
+		{input: "GB29NWBK6016133192681", wantErr: iban.ErrInvalidLength},
+		{input: "GB291WBK60161331926819", wantErr: iban.ErrInvalidFormat},
+		{input: "XX29NWBK60161331926819", wantErr: iban.ErrUnsupportedCountry},
+		{input: "GB", wantErr: iban.ErrInvalidLength},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			parsed, err := iban.Parse(tc.input)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v; want: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := parsed.BankCode(); got != tc.wantBank {
+				t.Errorf("bank code = %s; want: %s", got, tc.wantBank)
+			}
+			if got := parsed.BranchCode(); got != tc.wantBranch {
+				t.Errorf("branch code = %s; want: %s", got, tc.wantBranch)
+			}
+			if got := parsed.AccountNumber(); got != tc.wantAccount {
+				t.Errorf("account number = %s; want: %s", got, tc.wantAccount)
+			}
+		})
+	}
+}
+
+func TestIBAN_Format(t *testing.T) {
+	parsed, err := iban.Parse("GB29NWBK60161331926819")
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if got, want := parsed.String(), "GB29NWBK60161331926819"; got != want {
+		t.Errorf("electronic = %s; want: %s", got, want)
+	}
+	if got, want := parsed.Print(), "GB29 NWBK 6016 1331 9268 19"; got != want {
+		t.Errorf("print = %s; want: %s", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	testcases := []struct {
+		country       string
+		bankID        string
+		accountNumber string
+		want          string
+		wantErr       error
+	}{
+		{country: "GB", bankID: "NWBK601613", accountNumber: "31926819", want: "GB29NWBK60161331926819"},
+		{country: "DE", bankID: "37040044", accountNumber: "532013000", want: "DE89370400440532013000"},
+		{country: "NL", bankID: "ABNA", accountNumber: "417164300", want: "NL91ABNA0417164300"},
+		{country: "GB", bankID: "NWBK601613", accountNumber: "319268190", wantErr: iban.ErrInvalidLength},
+		{country: "DE", bankID: "ABCDEFGH", accountNumber: "532013000", wantErr: iban.ErrInvalidFormat},
+		{country: "US", bankID: "021000021", accountNumber: "1234", wantErr: iban.ErrUnsupportedCountry},
+		{country: "BE", bankID: "539", accountNumber: "007547034", wantErr: iban.ErrNationalCheckDigits},
+		{country: "FR", bankID: "2004101005", accountNumber: "0500013M02606", wantErr: iban.ErrNationalCheckDigits},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.country+tc.bankID+tc.accountNumber, func(t *testing.T) {
+			got, err := iban.New(tc.country, tc.bankID, tc.accountNumber)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v; want: %v", err, tc.wantErr)
+			}
+			if err == nil && got.String() != tc.want {
+				t.Errorf("iban = %s; want: %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package form3
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// AccountIterator walks through all pages of the account list. The next page is
+// fetched in the background while the current one is being consumed, so only
+// two pages are held in memory at a time.
+//
+//	it := c.Accounts.Iterate(ctx, &form3.ListAccountsOptions{PageSize: 100})
+//	for it.Next(ctx) {
+//		account := it.Account()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...handle the error...
+//	}
+//
+// Close must be called when the iteration is abandoned before Next returned false,
+// so the page fetched in the background is not left running.
+type AccountIterator struct {
+	fetch   func(ctx context.Context, link string) (*AccountListJSON, error)
+	ctx     context.Context
+	cancel  context.CancelFunc
+	page    []Account
+	index   int
+	account *Account
+	pending chan accountPage
+	done    bool
+	err     error
+}
+
+// accountPage represents the result of fetching a single page of accounts.
+type accountPage struct {
+	list *AccountListJSON
+	err  error
+}
+
+// Iterate returns an iterator over all accounts matching the given options.
+// The context governs the page requests made in the background; it is not checked
+// until the first call to Next.
+func (s *AccountsService) Iterate(ctx context.Context, opts *ListAccountsOptions) *AccountIterator {
+	endpoint := "/v1/organisation/accounts"
+	if query := opts.values().Encode(); query != "" {
+		endpoint += "?" + query
+	}
+	return NewAccountIterator(ctx, func(ctx context.Context, link string) (*AccountListJSON, error) {
+		if link == "" {
+			return s.list(ctx, endpoint)
+		}
+		next := strings.TrimPrefix(link, s.client.BaseURL())
+		if u, err := url.Parse(next); err == nil && u.IsAbs() {
+			next = u.RequestURI()
+		}
+		return s.list(ctx, next)
+	})
+}
+
+// NewAccountIterator returns an iterator over the pages of accounts returned by fetch,
+// e.g. to return from a mock of AccountsAPI.Iterate. The first page is fetched with an
+// empty link and every following page with the next link of the previous page. The
+// iteration ends after a page without a next link or without accounts.
+func NewAccountIterator(ctx context.Context, fetch func(ctx context.Context, link string) (*AccountListJSON, error)) *AccountIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &AccountIterator{fetch: fetch, ctx: ctx, cancel: cancel}
+	it.prefetch("")
+	return it
+}
+
+// Next advances the iterator to the next account. It returns false when there are
+// no more accounts or an error occurred. The context bounds how long Next waits
+// for the next page.
+func (it *AccountIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	for it.index >= len(it.page) {
+		if it.done {
+			it.account = nil
+			it.cancel()
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			it.err = ctx.Err()
+			return false
+		case page := <-it.pending:
+			if page.err != nil {
+				it.err = page.err
+				it.cancel()
+				return false
+			}
+			it.page, it.index = page.list.Data, 0
+			if next := it.nextLink(page.list.Links); next != "" {
+				it.prefetch(next)
+			} else {
+				it.done = true
+			}
+		}
+	}
+
+	it.account = &it.page[it.index]
+	it.index++
+	return true
+}
+
+// Account returns the current account. It is only valid after Next returned true.
+func (it *AccountIterator) Account() *Account {
+	return it.account
+}
+
+// Err returns the first error encountered during the iteration.
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and cancels the page request made in the background,
+// if any. Next returns false after Close. It is safe to call Close more than once.
+func (it *AccountIterator) Close() {
+	it.cancel()
+	it.page, it.index, it.account, it.done = nil, 0, nil, true
+}
+
+// prefetch starts fetching the page with the given link in the background.
+func (it *AccountIterator) prefetch(link string) {
+	pending := make(chan accountPage, 1)
+	it.pending = pending
+	go func() {
+		list, err := it.fetch(it.ctx, link)
+		pending <- accountPage{list: list, err: err}
+	}()
+}
+
+// nextLink returns the link to the next page or an empty string if the current page
+// is the last one.
+func (it *AccountIterator) nextLink(links *Links) string {
+	if links == nil || len(it.page) == 0 {
+		return ""
+	}
+	return links.Next
+}
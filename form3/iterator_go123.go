@@ -0,0 +1,30 @@
+//go:build go1.23
+
+package form3
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over all accounts matching the given options.
+// Iteration stops after the first error, which is yielded along with a nil account.
+// Breaking out of the loop cancels the page request made in the background.
+//
+//	for account, err := range c.Accounts.All(ctx, opts) {
+//		// ...
+//	}
+func (s *AccountsService) All(ctx context.Context, opts *ListAccountsOptions) iter.Seq2[*Account, error] {
+	return func(yield func(*Account, error) bool) {
+		it := s.Iterate(ctx, opts)
+		defer it.Close()
+		for it.Next(ctx) {
+			if !yield(it.Account(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
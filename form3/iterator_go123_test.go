@@ -0,0 +1,57 @@
+//go:build go1.23
+
+package form3_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/lmikolajczak/go-form3/form3"
+	"net/http"
+	"testing"
+)
+
+func TestAccountsService_All(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t)
+	defer teardown()
+	testAccountPages(t, mux, 5, 2)
+
+	count := 0
+	for account, err := range f3.Accounts.All(context.Background(), &form3.ListAccountsOptions{PageSize: 2}) {
+		if err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+		if account == nil {
+			t.Fatal("account = nil; want: account")
+		}
+		count++
+	}
+	if count != 5 {
+		t.Errorf("accounts = %d; want: 5", count)
+	}
+}
+
+func TestAccountsService_AllBreak(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t)
+	defer teardown()
+
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	mux.HandleFunc("/v1/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page[number]") == "1" {
+			close(started)
+			<-r.Context().Done()
+			close(cancelled)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"0"}],"links":{"next":"/v1/organisation/accounts?page[number]=1"}}`)
+	})
+
+	for _, err := range f3.Accounts.All(context.Background(), nil) {
+		if err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+		<-started
+		break
+	}
+	// Breaking out of the loop cancels the request for the next page.
+	<-cancelled
+}
@@ -0,0 +1,159 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lmikolajczak/go-form3/form3"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// testAccountPages registers a handler serving the given number of accounts split
+// into pages of the given size, linking them with links.next.
+func testAccountPages(t *testing.T, mux *http.ServeMux, total, size int) {
+	t.Helper()
+	mux.HandleFunc("/v1/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		number, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+		if got := r.URL.Query().Get("page[size]"); got != strconv.Itoa(size) {
+			t.Errorf("page[size] = %s; want: %d", got, size)
+		}
+
+		data := ""
+		for i := number * size; i < (number+1)*size && i < total; i++ {
+			if data != "" {
+				data += ","
+			}
+			data += fmt.Sprintf(`{"id":"%d"}`, i)
+		}
+		next := ""
+		if (number+1)*size < total {
+			next = fmt.Sprintf(`"next":"/v1/organisation/accounts?page[number]=%d&page[size]=%d"`, number+1, size)
+		}
+		fmt.Fprintf(w, `{"data":[%s],"links":{%s}}`, data, next)
+	})
+}
+
+func TestAccountIterator(t *testing.T) {
+	testcases := []struct {
+		name  string
+		total int
+		size  int
+	}{
+		{name: "no accounts", total: 0, size: 2},
+		{name: "single page", total: 2, size: 5},
+		{name: "multiple pages", total: 7, size: 2},
+		{name: "full last page", total: 6, size: 3},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f3, mux, teardown := form3.TestClientWithServer(t)
+			defer teardown()
+			testAccountPages(t, mux, tc.total, tc.size)
+
+			ctx := context.Background()
+			it := f3.Accounts.Iterate(ctx, &form3.ListAccountsOptions{PageSize: tc.size})
+
+			count := 0
+			for it.Next(ctx) {
+				if got, want := it.Account().ID, strconv.Itoa(count); got != want {
+					t.Errorf("account id = %s; want: %s", got, want)
+				}
+				count++
+			}
+			if err := it.Err(); err != nil {
+				t.Errorf("err = %v; want: nil", err)
+			}
+			if count != tc.total {
+				t.Errorf("accounts = %d; want: %d", count, tc.total)
+			}
+		})
+	}
+}
+
+func TestAccountIterator_Error(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t)
+	defer teardown()
+
+	mux.HandleFunc("/v1/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page[number]") == "1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"0"}],"links":{"next":"/v1/organisation/accounts?page[number]=1"}}`)
+	})
+
+	ctx := context.Background()
+	it := f3.Accounts.Iterate(ctx, nil)
+
+	count := 0
+	for it.Next(ctx) {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("accounts = %d; want: 1", count)
+	}
+	var f3Error *form3.F3Error
+	if err := it.Err(); !errors.As(err, &f3Error) || f3Error.StatusCode != http.StatusInternalServerError {
+		t.Errorf("err = %v; want: http 500", err)
+	}
+}
+
+func TestNewAccountIterator(t *testing.T) {
+	errFetch := errors.New("fetch")
+	pages := map[string]*form3.AccountListJSON{
+		"":       {Data: []form3.Account{{ID: "0"}, {ID: "1"}}, Links: &form3.Links{Next: "page-2"}},
+		"page-2": {Data: []form3.Account{{ID: "2"}}, Links: &form3.Links{Next: "page-3"}},
+	}
+	fetch := func(ctx context.Context, link string) (*form3.AccountListJSON, error) {
+		if page, ok := pages[link]; ok {
+			return page, nil
+		}
+		return nil, errFetch
+	}
+
+	ctx := context.Background()
+	it := form3.NewAccountIterator(ctx, fetch)
+	var ids []string
+	for it.Next(ctx) {
+		ids = append(ids, it.Account().ID)
+	}
+	if got := fmt.Sprint(ids); got != "[0 1 2]" {
+		t.Errorf("accounts = %s; want: [0 1 2]", got)
+	}
+	if err := it.Err(); !errors.Is(err, errFetch) {
+		t.Errorf("err = %v; want: %v", err, errFetch)
+	}
+}
+
+func TestAccountIterator_Close(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t)
+	defer teardown()
+
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	mux.HandleFunc("/v1/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page[number]") == "1" {
+			close(started)
+			// The next page is never served, the request is cancelled instead.
+			<-r.Context().Done()
+			close(cancelled)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"0"}],"links":{"next":"/v1/organisation/accounts?page[number]=1"}}`)
+	})
+
+	ctx := context.Background()
+	it := f3.Accounts.Iterate(ctx, nil)
+	if !it.Next(ctx) {
+		t.Fatalf("err = %v; want: account", it.Err())
+	}
+	<-started
+	it.Close()
+	<-cancelled
+
+	if it.Next(ctx) {
+		t.Error("next = true; want: false")
+	}
+}
@@ -0,0 +1,53 @@
+package form3
+
+import "net/http"
+
+// Operation identifies the Form3 REST API operation performed by a call.
+type Operation string
+
+// Operations performed by the services of the client. Requests made directly with
+// Client.Request have no operation.
+const (
+	OperationCreateAccount Operation = "CreateAccount"
+	OperationFetchAccount  Operation = "FetchAccount"
+	OperationUpdateAccount Operation = "UpdateAccount"
+	OperationDeleteAccount Operation = "DeleteAccount"
+	OperationListAccounts  Operation = "ListAccounts"
+)
+
+// Call represents a single call of the Form3 REST API passed through the middleware
+// chain.
+type Call struct {
+	// Operation is the operation performed by the call or empty if unknown.
+	Operation Operation
+	// Request is the http request of the call. Middlewares may modify it, e.g. add
+	// headers, before passing the call to the next handler.
+	Request *http.Request
+}
+
+// Handler performs the call. It returns the last http response received, if any, and
+// the decoded error, e.g. *F3Error, *TransportError or *DecodeError. The body of the
+// returned response is an in-memory copy of the body read by the client.
+type Handler func(call *Call) (*http.Response, error)
+
+// Middleware wraps the next handler in the chain, e.g. to log, measure, authenticate
+// or trace calls, or to inject faults.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middlewares around Client.Request. Middlewares are called in
+// the order they are added, i.e. the first middleware added is the outermost one and
+// sees the call first and its result last. Each call passes through the chain once;
+// retries of the call according to the RetryPolicy happen within the chain.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// chain wraps the handler in the client's middlewares.
+func (c *Client) chain(handler Handler) Handler {
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		handler = c.middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,80 @@
+package form3_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-test/deep"
+	"github.com/lmikolajczak/go-form3/form3"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) form3.Middleware {
+		return func(next form3.Handler) form3.Handler {
+			return func(call *form3.Call) (*http.Response, error) {
+				calls = append(calls, name+" "+string(call.Operation))
+				call.Request.Header.Add("X-Middleware", name)
+				response, err := next(call)
+				var f3Error *form3.F3Error
+				if errors.As(err, &f3Error) {
+					body, _ := io.ReadAll(response.Body)
+					response.Body = io.NopCloser(bytes.NewReader(body))
+					calls = append(calls, name+" "+string(body))
+				}
+				return response, err
+			}
+		}
+	}
+
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithMiddleware(record("outer"), record("inner")))
+	defer teardown()
+
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		if diff := deep.Equal(r.Header.Values("X-Middleware"), []string{"outer", "inner"}); diff != nil {
+			t.Error(diff)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error_message":"not found"}`))
+	})
+
+	_, err := f3.Accounts.Fetch(context.Background(), "ad27e265")
+	if !errors.Is(err, form3.ErrNotFound) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrNotFound)
+	}
+	want := []string{
+		"outer FetchAccount",
+		"inner FetchAccount",
+		`inner {"error_message":"not found"}`,
+		`outer {"error_message":"not found"}`,
+	}
+	if diff := deep.Equal(calls, want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestWithMiddleware_FaultInjection(t *testing.T) {
+	errInjected := errors.New("injected")
+	inject := func(next form3.Handler) form3.Handler {
+		return func(call *form3.Call) (*http.Response, error) {
+			if call.Operation == form3.OperationDeleteAccount {
+				return nil, errInjected
+			}
+			return next(call)
+		}
+	}
+
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithMiddleware(inject))
+	defer teardown()
+
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request %s %s; want: none", r.Method, r.URL)
+	})
+
+	if err := f3.Accounts.Delete(context.Background(), "ad27e265", 0); err != errInjected {
+		t.Errorf("err = %v; want: %v", err, errInjected)
+	}
+}
@@ -0,0 +1,191 @@
+package modulus
+
+import (
+	"strings"
+)
+
+// Weights substituted by exception 2.
+var (
+	exception2Weights   = [14]int{0, 0, 1, 2, 5, 3, 6, 4, 8, 7, 10, 9, 3, 1}
+	exception2WeightsG9 = [14]int{0, 0, 0, 0, 0, 0, 0, 0, 8, 7, 10, 9, 3, 1}
+)
+
+// Positions of the digits of the sort code (u-z) and account number (a-h).
+const (
+	posA = 6 + iota
+	posB
+	posC
+	posD
+	posE
+	posF
+	posG
+	posH
+)
+
+// Check checks the sort code and account number combination. Sort codes not covered
+// by the table cannot be checked and are reported as valid.
+func (t *Table) Check(sortCode, accountNumber string) error {
+	sortCode = strings.ReplaceAll(sortCode, "-", "")
+	if len(sortCode) != 6 || len(accountNumber) != 8 || !digits(sortCode+accountNumber) {
+		return ErrInvalidFormat
+	}
+	number := toDigits(sortCode + accountNumber)
+
+	rules := t.rulesFor(sortCode)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	// Exception 6: foreign currency accounts cannot be checked.
+	if rules[0].Exception == 6 && number[posA] >= 4 && number[posA] <= 8 && number[posG] == number[posH] {
+		return nil
+	}
+
+	first := t.check(rules[0], sortCode, number)
+	if len(rules) == 1 {
+		return result(first)
+	}
+	second := rules[1]
+
+	switch {
+	case rules[0].Exception == 2 && second.Exception == 9:
+		// Exception 2 & 9: the second check is only performed if the first one fails.
+		return result(first || t.check(second, "309634", number))
+	case rules[0].Exception == 10 && second.Exception == 11,
+		rules[0].Exception == 12 && second.Exception == 13:
+		// Valid if either of the checks passes.
+		return result(first || t.check(second, sortCode, number))
+	case second.Exception == 3 && (number[posC] == 6 || number[posC] == 9):
+		// Exception 3: the second check is skipped.
+		return result(first)
+	}
+	return result(first && t.check(second, sortCode, number))
+}
+
+// check performs a single check of the rule.
+func (t *Table) check(rule Rule, sortCode string, number [14]int) bool {
+	weights := rule.Weights
+	switch rule.Exception {
+	case 2:
+		if number[posA] != 0 {
+			if number[posG] != 9 {
+				weights = exception2Weights
+			} else {
+				weights = exception2WeightsG9
+			}
+		}
+	case 5:
+		if substituted, ok := t.substitutions[sortCode]; ok {
+			substitute(&number, substituted)
+		}
+	case 7:
+		if number[posG] == 9 {
+			zeroise(&weights)
+		}
+	case 8:
+		substitute(&number, "090126")
+	case 9:
+		substitute(&number, "309634")
+	case 10:
+		if (number[posA] == 0 || number[posA] == 9) && number[posB] == 9 && number[posG] == 9 {
+			zeroise(&weights)
+		}
+	}
+
+	total := sum(rule.Method, weights, number)
+	if rule.Exception == 1 {
+		total += 27
+	}
+
+	switch {
+	case rule.Exception == 4:
+		return total%11 == number[posG]*10+number[posH]
+	case rule.Exception == 5 && rule.Method == MOD11:
+		switch remainder := total % 11; remainder {
+		case 0:
+			return number[posG] == 0
+		case 1:
+			return false
+		default:
+			return 11-remainder == number[posG]
+		}
+	case rule.Exception == 5 && rule.Method == DBLAL:
+		if remainder := total % 10; remainder != 0 {
+			return 10-remainder == number[posH]
+		}
+		return number[posH] == 0
+	}
+
+	if valid(rule.Method, total) {
+		return true
+	}
+	// Exception 14: retry without the last digit for accounts ending with 0, 1 or 9.
+	if rule.Exception == 14 && (number[posH] == 0 || number[posH] == 1 || number[posH] == 9) {
+		copy(number[posB:], number[posA:posH])
+		number[posA] = 0
+		return valid(rule.Method, sum(rule.Method, weights, number))
+	}
+	return false
+}
+
+// sum returns the weighted sum of the digits according to the method.
+func sum(method Method, weights [14]int, number [14]int) int {
+	total := 0
+	for i := range number {
+		product := weights[i] * number[i]
+		if method == DBLAL {
+			product = product/10 + product%10
+		}
+		total += product
+	}
+	return total
+}
+
+// valid reports whether the weighted sum passes the check of the method.
+func valid(method Method, total int) bool {
+	if method == MOD11 {
+		return total%11 == 0
+	}
+	return total%10 == 0
+}
+
+// substitute replaces the sort code digits (u-z) of the number.
+func substitute(number *[14]int, sortCode string) {
+	substituted := toDigits(sortCode)
+	copy(number[:6], substituted[:6])
+}
+
+// zeroise sets the weights of the sort code and the first two digits of the account
+// number (u-b) to zero.
+func zeroise(weights *[14]int) {
+	for i := 0; i <= posB; i++ {
+		weights[i] = 0
+	}
+}
+
+// result converts the outcome of the check into an error.
+func result(valid bool) error {
+	if valid {
+		return nil
+	}
+	return ErrInvalidAccount
+}
+
+// digits reports whether s consists of digits only.
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// toDigits converts up to 14 digits into integers.
+func toDigits(s string) [14]int {
+	var number [14]int
+	for i := 0; i < len(s) && i < len(number); i++ {
+		number[i] = int(s[i] - '0')
+	}
+	return number
+}
@@ -0,0 +1,177 @@
+// Package modulus implements the Vocalink modulus checking of UK sort code and
+// account number combinations.
+//
+// The check is driven by a weight table in the valacdos.txt format published by
+// Vocalink. The package embeds a small sample table; production code should load
+// the current table with LoadTable or ParseTable and install it with SetDefault.
+package modulus
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Errors returned by the modulus check.
+var (
+	ErrInvalidFormat  = errors.New("modulus: sort code must have 6 and account number 8 digits")
+	ErrInvalidAccount = errors.New("modulus: account number does not pass the modulus check")
+)
+
+// Method is the algorithm of the modulus check.
+type Method string
+
+// Supported methods of the modulus check.
+const (
+	MOD10 Method = "MOD10"
+	MOD11 Method = "MOD11"
+	DBLAL Method = "DBLAL"
+)
+
+// Rule represents a single row of the weight table.
+type Rule struct {
+	From, To  string
+	Method    Method
+	Weights   [14]int
+	Exception int
+}
+
+// Table represents the modulus weight table along with the sort code substitutions
+// used by exception 5.
+type Table struct {
+	rules         []Rule
+	substitutions map[string]string
+}
+
+// ParseTable parses the weight table in the valacdos.txt format. Empty lines and
+// lines starting with # are ignored.
+func ParseTable(r io.Reader) (*Table, error) {
+	table := &Table{substitutions: map[string]string{}}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 17 || len(fields) > 18 {
+			return nil, fmt.Errorf("modulus: line %d: expected 17 or 18 fields, got %d", line, len(fields))
+		}
+
+		rule := Rule{From: fields[0], To: fields[1], Method: Method(fields[2])}
+		switch rule.Method {
+		case MOD10, MOD11, DBLAL:
+		default:
+			return nil, fmt.Errorf("modulus: line %d: unknown method %s", line, rule.Method)
+		}
+		for i, field := range fields[3:17] {
+			weight, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("modulus: line %d: invalid weight %s", line, field)
+			}
+			rule.Weights[i] = weight
+		}
+		if len(fields) == 18 {
+			exception, err := strconv.Atoi(fields[17])
+			if err != nil {
+				return nil, fmt.Errorf("modulus: line %d: invalid exception %s", line, fields[17])
+			}
+			rule.Exception = exception
+		}
+		table.rules = append(table.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Rules are searched by the sort code range, keeping the order of the rules
+	// sharing the same range.
+	sort.SliceStable(table.rules, func(i, j int) bool { return table.rules[i].From < table.rules[j].From })
+	return table, nil
+}
+
+// LoadTable parses the weight table from the file at the given path.
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseTable(f)
+}
+
+// ParseSubstitutions parses the sort code substitution table in the scsubtab.txt
+// format, i.e. lines with the original and the substituted sort code, and adds the
+// substitutions to the table.
+func (t *Table) ParseSubstitutions(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("modulus: line %d: expected 2 fields, got %d", line, len(fields))
+		}
+		t.substitutions[fields[0]] = fields[1]
+	}
+	return scanner.Err()
+}
+
+// rulesFor returns the rules applicable to the sort code.
+func (t *Table) rulesFor(sortCode string) []Rule {
+	var rules []Rule
+	for _, rule := range t.rules {
+		if rule.From > sortCode {
+			break
+		}
+		if sortCode <= rule.To {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+//go:embed valacdos.txt
+var sampleTable string
+
+var defaultTable = struct {
+	sync.RWMutex
+	table *Table
+}{}
+
+func init() {
+	table, err := ParseTable(strings.NewReader(sampleTable))
+	if err != nil {
+		panic(err)
+	}
+	defaultTable.table = table
+}
+
+// SetDefault installs the table used by CheckGBAccount.
+func SetDefault(table *Table) {
+	defaultTable.Lock()
+	defer defaultTable.Unlock()
+	defaultTable.table = table
+}
+
+// Default returns the table used by CheckGBAccount.
+func Default() *Table {
+	defaultTable.RLock()
+	defer defaultTable.RUnlock()
+	return defaultTable.table
+}
+
+// CheckGBAccount checks the sort code and account number combination using the
+// default table. Sort codes not covered by the table cannot be checked and are
+// reported as valid. Unless a table is installed with SetDefault, the embedded
+// sample table is used, which leaves virtually all real sort codes unchecked.
+func CheckGBAccount(sortCode, accountNumber string) error {
+	return Default().Check(sortCode, accountNumber)
+}
@@ -0,0 +1,131 @@
+package modulus_test
+
+import (
+	"errors"
+	"github.com/lmikolajczak/go-form3/form3/modulus"
+	"strings"
+	"testing"
+)
+
+func TestCheckGBAccount(t *testing.T) {
+	testcases := []struct {
+		name          string
+		sortCode      string
+		accountNumber string
+		wantErr       error
+	}{
+		{name: "MOD10", sortCode: "089999", accountNumber: "66374958"},
+		{name: "MOD10 invalid", sortCode: "089999", accountNumber: "66374959", wantErr: modulus.ErrInvalidAccount},
+		{name: "MOD11", sortCode: "107999", accountNumber: "88837491"},
+		{name: "MOD11 invalid", sortCode: "107999", accountNumber: "88837493", wantErr: modulus.ErrInvalidAccount},
+		{name: "DBLAL", sortCode: "20-29-59", accountNumber: "63748472"},
+		{name: "DBLAL invalid", sortCode: "202959", accountNumber: "63748473", wantErr: modulus.ErrInvalidAccount},
+		{name: "sort code not in table", sortCode: "400300", accountNumber: "12345678"},
+		{name: "invalid format", sortCode: "4003", accountNumber: "12345678", wantErr: modulus.ErrInvalidFormat},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := modulus.CheckGBAccount(tc.sortCode, tc.accountNumber); !errors.Is(err, tc.wantErr) {
+				t.Errorf("err = %v; want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestTable_CheckExceptions(t *testing.T) {
+	// Rows of the exceptions 4, 5, 6 and 14 are those of the test cases of the
+	// Vocalink specification. Rows of the other exceptions use the standard weights.
+	table, err := modulus.ParseTable(strings.NewReader(`
+074456 074456 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 12
+074456 074456 MOD10 0 0 0 0 0 0 7 1 3 7 1 3 7 1 13
+086090 086090 MOD11 7 6 5 4 3 2 8 7 6 5 4 3 2 1 8
+110000 119999 DBLAL 0 0 0 0 0 0 2 1 2 1 2 1 2 1 1
+134012 134020 MOD11 0 0 0 7 5 9 8 4 6 3 5 2 0 0 4
+180002 180002 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 14
+200915 200915 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 6
+200915 200915 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 2 1
+309070 309070 MOD11 0 0 1 2 5 3 6 4 8 7 10 9 3 1 2
+309070 309070 MOD11 0 0 1 2 5 3 6 4 8 7 10 9 3 1 9
+772798 772798 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 7
+820000 829999 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1
+820000 829999 DBLAL 0 0 0 0 0 0 2 1 2 1 2 1 2 1 3
+871427 871427 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 10
+871427 871427 MOD11 0 0 0 0 0 0 0 0 7 6 5 4 3 2 11
+938000 938696 MOD11 7 6 5 4 3 2 7 6 5 4 3 2 0 0 5
+938000 938696 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 2 0 5
+`))
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if err = table.ParseSubstitutions(strings.NewReader("938600 938611\n")); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+
+	testcases := []struct {
+		name          string
+		sortCode      string
+		accountNumber string
+		wantErr       error
+	}{
+		{name: "exception 1 adds 27", sortCode: "118765", accountNumber: "52601815"},
+		{name: "exception 1 standard check", sortCode: "118765", accountNumber: "62527601", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 2 a=0 row weights", sortCode: "309070", accountNumber: "02104031"},
+		{name: "exception 2 a<>0 g<>9 weights", sortCode: "309070", accountNumber: "43195476"},
+		{name: "exception 2 a<>0 g=9 weights", sortCode: "309070", accountNumber: "26109698"},
+		{name: "exception 2 & 9 second check with substitution", sortCode: "309070", accountNumber: "57938763"},
+		{name: "exception 2 & 9 both checks fail", sortCode: "309070", accountNumber: "55625043", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 3 c=6 second check skipped", sortCode: "820000", accountNumber: "17603137"},
+		{name: "exception 3 second check fails", sortCode: "820000", accountNumber: "21590109", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 3 both checks pass", sortCode: "827101", accountNumber: "82276544"},
+		{name: "exception 4 remainder equals check digits", sortCode: "134020", accountNumber: "63849203"},
+		{name: "exception 5 check passes", sortCode: "938611", accountNumber: "07806039"},
+		{name: "exception 5 check passes with substitution", sortCode: "938600", accountNumber: "42368003"},
+		{name: "exception 5 both remainders 0", sortCode: "938063", accountNumber: "55065200"},
+		{name: "exception 5 second check digit incorrect", sortCode: "938063", accountNumber: "15764273", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 5 first check digit incorrect", sortCode: "938063", accountNumber: "15764264", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 5 remainder 1", sortCode: "938063", accountNumber: "15763217", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 6 foreign currency account", sortCode: "200915", accountNumber: "41011166"},
+		{name: "exception 6 both checks", sortCode: "200915", accountNumber: "31011166", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 7 g=9 zeroised weights", sortCode: "772798", accountNumber: "22832391"},
+		{name: "exception 7 g<>9 standard weights", sortCode: "772798", accountNumber: "22144800", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 8 substituted sort code", sortCode: "086090", accountNumber: "16289332"},
+		{name: "exception 10 & 11 first check passes", sortCode: "871427", accountNumber: "77403533"},
+		{name: "exception 10 & 11 second check passes", sortCode: "871427", accountNumber: "32655239"},
+		{name: "exception 10 & 11 both checks fail", sortCode: "871427", accountNumber: "94817426", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 10 ab=09 g=9 zeroised weights", sortCode: "871427", accountNumber: "09627593"},
+		{name: "exception 10 ab=99 g=9 zeroised weights", sortCode: "871427", accountNumber: "99268496"},
+		{name: "exception 12 & 13 first check passes", sortCode: "074456", accountNumber: "68802250"},
+		{name: "exception 12 & 13 second check passes", sortCode: "074456", accountNumber: "22326940"},
+		{name: "exception 12 & 13 both checks fail", sortCode: "074456", accountNumber: "56367009", wantErr: modulus.ErrInvalidAccount},
+		{name: "exception 14 shifted account", sortCode: "180002", accountNumber: "00000190"},
+		{name: "exception 14 invalid last digit", sortCode: "180002", accountNumber: "00000192", wantErr: modulus.ErrInvalidAccount},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := table.Check(tc.sortCode, tc.accountNumber); !errors.Is(err, tc.wantErr) {
+				t.Errorf("err = %v; want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	testcases := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing weights", input: "089000 089999 MOD10 0 0 0"},
+		{name: "unknown method", input: "089000 089999 MOD12 0 0 0 0 0 0 7 1 3 7 1 3 7 1"},
+		{name: "invalid weight", input: "089000 089999 MOD10 0 0 0 0 0 0 7 1 3 7 1 3 7 X"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := modulus.ParseTable(strings.NewReader(tc.input)); err == nil {
+				t.Error("err = nil; want: error")
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultConflictRetries is the number of times Mutate and DeleteCurrent re-fetch the
+// account and retry after a version conflict, unless configured otherwise.
+const DefaultConflictRetries = 3
+
+// ErrPreconditionFailed is returned by Mutate and DeleteCurrent if the fetched account
+// does not satisfy a precondition, e.g. it does not have the required status.
+var ErrPreconditionFailed = errors.New("form3: precondition failed")
+
+// MutateOption configures Mutate and DeleteCurrent.
+type MutateOption func(*mutateOptions)
+
+type mutateOptions struct {
+	conflictRetries int
+	preconditions   []func(*Account) error
+}
+
+// WithConflictRetries sets the number of times the account is re-fetched and the
+// changes are reapplied after a version conflict. Zero disables retrying.
+func WithConflictRetries(n int) MutateOption {
+	return func(o *mutateOptions) {
+		o.conflictRetries = n
+	}
+}
+
+// WithPrecondition adds a check of the fetched account run before it is modified or
+// deleted. The check is repeated after every re-fetch. Errors returned by the check
+// abort the operation and are returned as is.
+func WithPrecondition(check func(*Account) error) MutateOption {
+	return func(o *mutateOptions) {
+		o.preconditions = append(o.preconditions, check)
+	}
+}
+
+// WithStatus requires the account to have one of the given statuses. Otherwise the
+// operation fails with ErrPreconditionFailed.
+func WithStatus(statuses ...string) MutateOption {
+	return WithPrecondition(func(account *Account) error {
+		if status := accountStatus(account); !contains(statuses, status) {
+			return fmt.Errorf("%w: account status %q is not %s", ErrPreconditionFailed, status, strings.Join(statuses, " or "))
+		}
+		return nil
+	})
+}
+
+// WithoutStatus requires the account not to have any of the given statuses, e.g.
+// WithoutStatus("confirmed"). Otherwise the operation fails with ErrPreconditionFailed.
+func WithoutStatus(statuses ...string) MutateOption {
+	return WithPrecondition(func(account *Account) error {
+		if status := accountStatus(account); contains(statuses, status) {
+			return fmt.Errorf("%w: account status is %q", ErrPreconditionFailed, status)
+		}
+		return nil
+	})
+}
+
+// accountStatus returns the status of the account or an empty string if it is not set.
+func accountStatus(account *Account) string {
+	if account.Attributes == nil || account.Attributes.Status == nil {
+		return ""
+	}
+	return *account.Attributes.Status
+}
+
+// Mutate fetches the account with the given identifier, applies the mutate function
+// to it and updates the changed attributes using the fetched version. If the account
+// was modified in the meantime and the API responds with 409 Conflict, the account is
+// re-fetched and the mutate function is applied again, up to the configured number of
+// retries (DefaultConflictRetries by default).
+//
+// Only changes of the attributes are written. Attributes set to their zero value are
+// cleared. If the mutate function does not change any attribute, the fetched account
+// is returned without an update. Errors returned by the mutate function abort Mutate
+// and are returned as is.
+func (s *AccountsService) Mutate(ctx context.Context, id string, mutate func(*Account) error, options ...MutateOption) (*Account, error) {
+	opts := newMutateOptions(options)
+	for attempt := 0; ; attempt++ {
+		account, err := s.fetchCurrent(ctx, id, opts)
+		if err != nil {
+			return nil, err
+		}
+		if account.Attributes == nil {
+			account.Attributes = new(AccountAttributes)
+		}
+
+		// The fetched version is kept aside, so the mutate function cannot bypass the
+		// optimistic lock by changing it.
+		version := *account.Version
+		before, err := attributeFields(account.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		if err = mutate(account); err != nil {
+			return nil, err
+		}
+		after, err := attributeFields(account.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		changes := diffFields(before, after)
+		if len(changes) == 0 {
+			return account, nil
+		}
+
+		updated, err := s.update(ctx, id, version, changes)
+		if err == nil || !errors.Is(err, ErrConflict) || attempt >= opts.conflictRetries {
+			return updated, err
+		}
+	}
+}
+
+// DeleteCurrent deletes the account with the given identifier without knowing its
+// version. The account is fetched and deleted using the fetched version. If the
+// account was modified in the meantime and the API responds with 409 Conflict, it is
+// re-fetched and the deletion is retried, up to the configured number of retries
+// (DefaultConflictRetries by default). Use WithoutStatus or WithStatus to delete the
+// account only in a particular state.
+func (s *AccountsService) DeleteCurrent(ctx context.Context, id string, options ...MutateOption) error {
+	opts := newMutateOptions(options)
+	for attempt := 0; ; attempt++ {
+		account, err := s.fetchCurrent(ctx, id, opts)
+		if err != nil {
+			return err
+		}
+
+		err = s.Delete(ctx, id, *account.Version)
+		if err == nil || !errors.Is(err, ErrConflict) || attempt >= opts.conflictRetries {
+			return err
+		}
+	}
+}
+
+// newMutateOptions returns the defaults overridden by the options.
+func newMutateOptions(options []MutateOption) mutateOptions {
+	opts := mutateOptions{conflictRetries: DefaultConflictRetries}
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
+// fetchCurrent fetches the current version of the account and checks the preconditions.
+func (s *AccountsService) fetchCurrent(ctx context.Context, id string, opts mutateOptions) (*Account, error) {
+	account, err := s.Fetch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account.Version == nil {
+		return nil, errors.New("form3: fetched account has no version")
+	}
+	for _, check := range opts.preconditions {
+		if err = check(account); err != nil {
+			return nil, err
+		}
+	}
+	return account, nil
+}
+
+// attributeFields returns the JSON encoded fields of the attributes.
+func attributeFields(attributes *AccountAttributes) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// diffFields returns the fields changed between before and after. Fields missing
+// in after are set to null.
+func diffFields(before, after map[string]json.RawMessage) map[string]json.RawMessage {
+	changes := map[string]json.RawMessage{}
+	for name, value := range after {
+		if previous, ok := before[name]; !ok || !equalJSON(previous, value) {
+			changes[name] = value
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changes[name] = json.RawMessage("null")
+		}
+	}
+	return changes
+}
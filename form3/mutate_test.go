@@ -0,0 +1,202 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lmikolajczak/go-form3/form3"
+	"github.com/lmikolajczak/go-form3/form3/form3test"
+	"net/http"
+	"testing"
+)
+
+func TestAccountsService_Mutate(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	id := uuid.NewString()
+	server.PutAccount(form3.Account{
+		ID:         id,
+		Attributes: &form3.AccountAttributes{Country: form3.String("IE"), Name: []string{"Old Name"}, SecondaryIdentification: "Secondary"},
+	})
+
+	// Another client modifies the account after the first fetch.
+	modified := false
+	server.AddHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodPatch && !modified {
+			modified = true
+			account, _ := server.Account(id)
+			*account.Version++
+			account.Attributes.Status = form3.String("confirmed")
+			server.PutAccount(*account)
+		}
+		return false
+	})
+
+	calls := 0
+	account, err := f3.Accounts.Mutate(context.Background(), id, func(account *form3.Account) error {
+		calls++
+		account.Attributes.Name = []string{"New Name"}
+		account.Attributes.SecondaryIdentification = ""
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d; want: 2", calls)
+	}
+	if account.Version == nil || *account.Version != 2 {
+		t.Errorf("version = %v; want: 2", account.Version)
+	}
+	attributes := account.Attributes
+	if attributes.Name[0] != "New Name" || attributes.SecondaryIdentification != "" || attributes.Status == nil || *attributes.Status != "confirmed" {
+		t.Errorf("attributes = %+v; want: new name, no secondary identification, confirmed", attributes)
+	}
+}
+
+func TestAccountsService_MutateUnchanged(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	id := uuid.NewString()
+	server.PutAccount(form3.Account{ID: id, Attributes: &form3.AccountAttributes{Country: form3.String("IE"), Name: []string{"Name"}}})
+
+	_, err := f3.Accounts.Mutate(context.Background(), id, func(account *form3.Account) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if got := server.Requests(); got != 1 {
+		t.Errorf("requests = %d; want: 1", got)
+	}
+
+	errAbort := errors.New("abort")
+	_, err = f3.Accounts.Mutate(context.Background(), id, func(account *form3.Account) error {
+		return errAbort
+	})
+	if err != errAbort {
+		t.Errorf("err = %v; want: %v", err, errAbort)
+	}
+}
+
+func TestAccountsService_MutateVersion(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	id := uuid.NewString()
+	server.PutAccount(form3.Account{ID: id, Attributes: &form3.AccountAttributes{Country: form3.String("IE"), Name: []string{"Name"}}})
+
+	// Changes of the version by the mutate function are ignored.
+	stale := int64(5)
+	for i, version := range []*int64{nil, &stale} {
+		account, err := f3.Accounts.Mutate(context.Background(), id, func(account *form3.Account) error {
+			account.Version = version
+			account.Attributes.Name = []string{fmt.Sprintf("Name %d", i)}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+		if account.Version == nil || *account.Version != int64(i+1) {
+			t.Errorf("version = %v; want: %d", account.Version, i+1)
+		}
+	}
+}
+
+func TestAccountsService_MutateConflictRetries(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	id := uuid.NewString()
+	server.PutAccount(form3.Account{ID: id, Attributes: &form3.AccountAttributes{Country: form3.String("IE"), Name: []string{"Name"}}})
+	server.AddHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodPatch {
+			return false
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error_message":"invalid version"}`))
+		return true
+	})
+
+	calls := 0
+	_, err := f3.Accounts.Mutate(context.Background(), id, func(account *form3.Account) error {
+		calls++
+		account.Attributes.Name = []string{"New Name"}
+		return nil
+	}, form3.WithConflictRetries(1))
+	if !errors.Is(err, form3.ErrConflict) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrConflict)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d; want: 2", calls)
+	}
+}
+
+func TestAccountsService_DeleteCurrent(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	id := uuid.NewString()
+	server.PutAccount(form3.Account{ID: id, Attributes: &form3.AccountAttributes{Country: form3.String("IE"), Status: form3.String("pending")}})
+
+	// Another client modifies the account after the first fetch.
+	modified := false
+	server.AddHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodDelete && !modified {
+			modified = true
+			account, _ := server.Account(id)
+			*account.Version++
+			server.PutAccount(*account)
+		}
+		return false
+	})
+
+	if err := f3.Accounts.DeleteCurrent(context.Background(), id, form3.WithoutStatus("confirmed")); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if _, ok := server.Account(id); ok {
+		t.Errorf("account %s exists; want: deleted", id)
+	}
+	// Fetch, conflicting delete, fetch, delete.
+	if got := server.Requests(); got != 4 {
+		t.Errorf("requests = %d; want: 4", got)
+	}
+}
+
+func TestAccountsService_DeleteCurrentPrecondition(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3 := server.Client()
+
+	id := uuid.NewString()
+	server.PutAccount(form3.Account{ID: id, Attributes: &form3.AccountAttributes{Country: form3.String("IE"), Status: form3.String("confirmed")}})
+
+	testcases := []struct {
+		name   string
+		option form3.MutateOption
+	}{
+		{name: "without status", option: form3.WithoutStatus("confirmed")},
+		{name: "with status", option: form3.WithStatus("pending", "failed")},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := f3.Accounts.DeleteCurrent(context.Background(), id, tc.option)
+			if !errors.Is(err, form3.ErrPreconditionFailed) {
+				t.Errorf("err = %v; want: %v", err, form3.ErrPreconditionFailed)
+			}
+			if _, ok := server.Account(id); !ok {
+				t.Errorf("account %s deleted; want: exists", id)
+			}
+		})
+	}
+}
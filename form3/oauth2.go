@@ -0,0 +1,251 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultExpiryDelta is how long before its expiry a cached token is refreshed.
+const DefaultExpiryDelta = 30 * time.Second
+
+// Token is an OAuth2 bearer token.
+type Token struct {
+	AccessToken string
+	TokenType   string
+	// Expiry is the time the token expires at or zero if it does not expire.
+	Expiry time.Time
+}
+
+// TokenSource provides bearer tokens authenticating requests of the client.
+type TokenSource interface {
+	// Token returns a valid token.
+	Token(ctx context.Context) (*Token, error)
+	// Invalidate discards the token rejected by the API, so the next call of Token
+	// returns a new one.
+	Invalidate(token *Token)
+}
+
+// WithTokenSource makes the client authenticate requests with bearer tokens from the
+// given source. If the API responds with 401 Unauthorized, the token is invalidated
+// and the request is retried once with a new token.
+func WithTokenSource(source TokenSource) ClientOption {
+	return func(c *Client) {
+		c.tokenSource = source
+	}
+}
+
+// TokenError represents an error response of the token endpoint.
+type TokenError struct {
+	StatusCode  int
+	ErrorCode   string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Error returns a string representation of the TokenError.
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("form3: token endpoint responded with http %d: %s %s", e.StatusCode, e.ErrorCode, e.Description)
+}
+
+// Is reports whether the target is ErrUnauthorized for rejected client credentials.
+func (e *TokenError) Is(target error) bool {
+	return target == ErrUnauthorized && (e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnauthorized)
+}
+
+// ClientCredentialsOption represents an option that can be used to configure
+// ClientCredentialsTokenSource.
+type ClientCredentialsOption func(*ClientCredentialsTokenSource)
+
+// WithScopes sets the scopes requested with the token.
+func WithScopes(scopes ...string) ClientCredentialsOption {
+	return func(s *ClientCredentialsTokenSource) {
+		s.scopes = scopes
+	}
+}
+
+// WithTokenHTTPClient sets the HTTP client used to talk to the token endpoint.
+func WithTokenHTTPClient(httpClient HTTPClient) ClientCredentialsOption {
+	return func(s *ClientCredentialsTokenSource) {
+		s.httpClient = httpClient
+	}
+}
+
+// WithExpiryDelta sets how long before its expiry a cached token is refreshed.
+func WithExpiryDelta(delta time.Duration) ClientCredentialsOption {
+	return func(s *ClientCredentialsTokenSource) {
+		s.expiryDelta = delta
+	}
+}
+
+// ClientCredentialsTokenSource is a TokenSource performing OAuth2 client credentials
+// grants against a token endpoint. Tokens are cached until shortly before they expire.
+// Concurrent callers share a single request to the token endpoint.
+type ClientCredentialsTokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	httpClient   HTTPClient
+	expiryDelta  time.Duration
+	now          func() time.Time
+
+	mu      sync.Mutex
+	token   *Token
+	pending *tokenRequest
+}
+
+// tokenRequest is a request to the token endpoint shared by concurrent callers.
+type tokenRequest struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewClientCredentialsTokenSource returns a new token source authenticating with the
+// given client credentials at the token endpoint, e.g.
+// https://api.form3.tech/v1/oauth2/token.
+func NewClientCredentialsTokenSource(tokenURL, clientID, clientSecret string, options ...ClientCredentialsOption) *ClientCredentialsTokenSource {
+	s := &ClientCredentialsTokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient: &http.Client{
+			Timeout: DefaultTimeout,
+		},
+		expiryDelta: DefaultExpiryDelta,
+		now:         time.Now,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Token returns the cached token or requests a new one if it is missing or about to
+// expire. The context only limits waiting for the token; the request to the token
+// endpoint is shared with other callers and is not cancelled with it.
+func (s *ClientCredentialsTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	if s.token != nil && s.valid(s.token) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	pending := s.pending
+	if pending == nil {
+		pending = &tokenRequest{done: make(chan struct{})}
+		s.pending = pending
+		go s.refresh(pending)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-pending.done:
+		return pending.token, pending.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate discards the token if it is still cached.
+func (s *ClientCredentialsTokenSource) Invalidate(token *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+// valid reports whether the token does not expire within the expiry delta.
+func (s *ClientCredentialsTokenSource) valid(token *Token) bool {
+	return token.Expiry.IsZero() || s.now().Add(s.expiryDelta).Before(token.Expiry)
+}
+
+// refresh requests a new token, caches it and completes the pending request.
+func (s *ClientCredentialsTokenSource) refresh(pending *tokenRequest) {
+	token, err := s.retrieve()
+
+	s.mu.Lock()
+	if err == nil {
+		s.token = token
+	}
+	s.pending = nil
+	s.mu.Unlock()
+
+	pending.token, pending.err = token, err
+	close(pending.done)
+}
+
+// retrieve performs the client credentials grant.
+func (s *ClientCredentialsTokenSource) retrieve() (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.scopes) > 0 {
+		form.Set("scope", strings.Join(s.scopes, " "))
+	}
+	request, err := http.NewRequest(http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	requestedAt := s.now()
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return nil, &TransportError{Method: request.Method, URL: request.URL.String(), Err: err}
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, &TransportError{Method: request.Method, URL: request.URL.String(), Err: err}
+	}
+
+	if response.StatusCode != http.StatusOK {
+		tokenError := &TokenError{StatusCode: response.StatusCode}
+		if err = json.Unmarshal(body, tokenError); err != nil {
+			tokenError.Description = string(body)
+		}
+		return nil, tokenError
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err = json.Unmarshal(body, &payload); err != nil {
+		return nil, &DecodeError{Body: body, Err: err}
+	}
+	if payload.AccessToken == "" {
+		return nil, &DecodeError{Body: body, Err: fmt.Errorf("missing access_token")}
+	}
+
+	token := &Token{AccessToken: payload.AccessToken, TokenType: payload.TokenType}
+	if payload.ExpiresIn > 0 {
+		token.Expiry = requestedAt.Add(time.Duration(payload.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// authorize sets the Authorization header of the request to a bearer token from the
+// client's token source. It returns the token or nil if the client has no source.
+func (c *Client) authorize(request *http.Request) (*Token, error) {
+	if c.tokenSource == nil {
+		return nil, nil
+	}
+	token, err := c.tokenSource.Token(request.Context())
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return token, nil
+}
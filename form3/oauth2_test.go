@@ -0,0 +1,196 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lmikolajczak/go-form3/form3"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTokenServer starts a stand-in token endpoint issuing tokens token-1, token-2,
+// etc. valid for expiresIn seconds. It returns the server and the number of issued tokens.
+func testTokenServer(t *testing.T, expiresIn int, delay time.Duration) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var issued int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "client-id" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"unknown client"}`))
+			return
+		}
+		if r.PostFormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"unsupported_grant_type"}`))
+			return
+		}
+
+		time.Sleep(delay)
+		n := atomic.AddInt32(&issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func TestClientCredentialsTokenSource_Token(t *testing.T) {
+	server, issued := testTokenServer(t, 3600, 0)
+	source := form3.NewClientCredentialsTokenSource(server.URL, "client-id", "client-secret")
+
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+		if token.AccessToken != "token-1" {
+			t.Errorf("token = %s; want: token-1", token.AccessToken)
+		}
+	}
+	if got := atomic.LoadInt32(issued); got != 1 {
+		t.Errorf("issued tokens = %d; want: 1", got)
+	}
+
+	// Tokens expiring within the expiry delta are refreshed.
+	source = form3.NewClientCredentialsTokenSource(server.URL, "client-id", "client-secret", form3.WithExpiryDelta(2*time.Hour))
+	for i := 0; i < 2; i++ {
+		if _, err := source.Token(context.Background()); err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+	}
+	if got := atomic.LoadInt32(issued); got != 3 {
+		t.Errorf("issued tokens = %d; want: 3", got)
+	}
+}
+
+func TestClientCredentialsTokenSource_TokenConcurrent(t *testing.T) {
+	server, issued := testTokenServer(t, 3600, 50*time.Millisecond)
+	source := form3.NewClientCredentialsTokenSource(server.URL, "client-id", "client-secret")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := source.Token(context.Background()); err != nil {
+				t.Errorf("err = %v; want: nil", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(issued); got != 1 {
+		t.Errorf("issued tokens = %d; want: 1", got)
+	}
+}
+
+func TestClientCredentialsTokenSource_TokenError(t *testing.T) {
+	server, _ := testTokenServer(t, 3600, 0)
+	source := form3.NewClientCredentialsTokenSource(server.URL, "client-id", "wrong-secret")
+
+	_, err := source.Token(context.Background())
+	var tokenError *form3.TokenError
+	if !errors.As(err, &tokenError) || tokenError.ErrorCode != "invalid_client" {
+		t.Errorf("err = %v; want: invalid_client", err)
+	}
+	if !errors.Is(err, form3.ErrUnauthorized) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrUnauthorized)
+	}
+}
+
+func TestClient_RequestWithTokenSource(t *testing.T) {
+	server, issued := testTokenServer(t, 3600, 0)
+	source := form3.NewClientCredentialsTokenSource(server.URL, "client-id", "client-secret")
+
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithTokenSource(source))
+	defer teardown()
+
+	var authorizations []string
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		// The first token has been revoked.
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error_message":"invalid token"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"ad27e265"}}`))
+	})
+
+	if _, err := f3.Accounts.Fetch(context.Background(), "ad27e265"); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if got := atomic.LoadInt32(issued); got != 2 {
+		t.Errorf("issued tokens = %d; want: 2", got)
+	}
+	if len(authorizations) != 2 || authorizations[0] != "Bearer token-1" {
+		t.Errorf("authorizations = %v; want: [Bearer token-1 Bearer token-2]", authorizations)
+	}
+}
+
+func TestClient_RequestWithTokenSourceRetriesOnce(t *testing.T) {
+	server, issued := testTokenServer(t, 3600, 0)
+	source := form3.NewClientCredentialsTokenSource(server.URL, "client-id", "client-secret")
+
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithTokenSource(source))
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error_message":"invalid token"}`))
+	})
+
+	_, err := f3.Accounts.Fetch(context.Background(), "ad27e265")
+	if !errors.Is(err, form3.ErrUnauthorized) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrUnauthorized)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d; want: 2", requests)
+	}
+	if got := atomic.LoadInt32(issued); got != 2 {
+		t.Errorf("issued tokens = %d; want: 2", got)
+	}
+}
+
+func TestClient_RequestWithTokenSourceAndSigner(t *testing.T) {
+	source := form3.NewClientCredentialsTokenSource("http://localhost", "client-id", "client-secret")
+	signer := signerFunc(func(r *http.Request) error {
+		r.Header.Set("Authorization", "Signature")
+		return nil
+	})
+
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithTokenSource(source), form3.WithSigner(signer))
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"ad27e265"}}`))
+	})
+
+	if _, err := f3.Accounts.Fetch(context.Background(), "ad27e265"); err == nil {
+		t.Errorf("err = nil; want: configuration error")
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d; want: 0", requests)
+	}
+}
+
+// signerFunc adapts a function to the form3.Signer interface.
+type signerFunc func(r *http.Request) error
+
+func (f signerFunc) Sign(r *http.Request) error {
+	return f(r)
+}
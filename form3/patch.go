@@ -0,0 +1,128 @@
+package form3
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Nullable represents a field of a patch that can be left unchanged, set to a value
+// or cleared to null. The zero value leaves the field unchanged.
+//
+// On its own, the zero value is encoded as null, which clears the field. Nullable
+// fields must therefore be used in a patch type, e.g. AccountAttributesPatch, or
+// be tagged with omitzero, so unchanged fields are omitted.
+type Nullable[T any] struct {
+	value T
+	set   bool
+	null  bool
+}
+
+// Set returns a Nullable that sets the field to the given value v.
+func Set[T any](v T) Nullable[T] { return Nullable[T]{value: v, set: true} }
+
+// Null returns a Nullable that clears the field.
+func Null[T any]() Nullable[T] { return Nullable[T]{set: true, null: true} }
+
+// IsSet reports whether the field is modified, i.e. set to a value or cleared.
+func (n Nullable[T]) IsSet() bool { return n.set }
+
+// IsZero reports whether the field is left unchanged. It makes the omitzero option
+// of encoding/json omit the field.
+func (n Nullable[T]) IsZero() bool { return !n.set }
+
+// IsNull reports whether the field is cleared.
+func (n Nullable[T]) IsNull() bool { return n.null }
+
+// Value returns the value of the field and reports whether it is set to a value.
+func (n Nullable[T]) Value() (T, bool) { return n.value, n.set && !n.null }
+
+// MarshalJSON returns the JSON encoding of the value or null if the field is cleared.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.set || n.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.value)
+}
+
+// UnmarshalJSON sets the field to the decoded value or clears it on null.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*n = Null[T]()
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*n = Set(value)
+	return nil
+}
+
+// patchField is implemented by every Nullable.
+type patchField interface {
+	IsSet() bool
+}
+
+// AccountAttributesPatch represents modifications of account attributes. Only the
+// fields that are set (see Set and Null) are sent to the API.
+type AccountAttributesPatch struct {
+	AcceptanceQualifier        Nullable[string]                      `json:"acceptance_qualifier"`
+	AccountClassification      Nullable[string]                      `json:"account_classification"`
+	AccountMatchingOptOut      Nullable[bool]                        `json:"account_matching_opt_out"`
+	AccountNumber              Nullable[string]                      `json:"account_number"`
+	AlternativeNames           Nullable[[]string]                    `json:"alternative_names"`
+	BankID                     Nullable[string]                      `json:"bank_id"`
+	BankIDCode                 Nullable[string]                      `json:"bank_id_code"`
+	BaseCurrency               Nullable[string]                      `json:"base_currency"`
+	Bic                        Nullable[string]                      `json:"bic"`
+	Country                    Nullable[string]                      `json:"country"`
+	Iban                       Nullable[string]                      `json:"iban"`
+	JointAccount               Nullable[bool]                        `json:"joint_account"`
+	Name                       Nullable[[]string]                    `json:"name"`
+	NameMatchingStatus         Nullable[string]                      `json:"name_matching_status"`
+	OrganisationIdentification Nullable[*OrganisationIdentification] `json:"organisation_identification"`
+	PrivateIdentification      Nullable[*PrivateIdentification]      `json:"private_identification"`
+	ProcessingService          Nullable[string]                      `json:"processing_service"`
+	ReferenceMask              Nullable[string]                      `json:"reference_mask"`
+	SecondaryIdentification    Nullable[string]                      `json:"secondary_identification"`
+	Status                     Nullable[string]                      `json:"status"`
+	StatusReason               Nullable[string]                      `json:"status_reason"`
+	Switched                   Nullable[bool]                        `json:"switched"`
+	UserDefinedData            Nullable[[]UserDefinedData]           `json:"user_defined_data"`
+	ValidationType             Nullable[string]                      `json:"validation_type"`
+}
+
+// MarshalJSON returns the JSON encoding of the fields that are set.
+func (p AccountAttributesPatch) MarshalJSON() ([]byte, error) {
+	return marshalPatch(p)
+}
+
+// marshalPatch returns the JSON encoding of the struct v omitting the fields that
+// are not set.
+func marshalPatch(v interface{}) ([]byte, error) {
+	fields := map[string]interface{}{}
+	value := reflect.ValueOf(v)
+	for i := 0; i < value.NumField(); i++ {
+		field, ok := value.Field(i).Interface().(patchField)
+		if !ok || !field.IsSet() {
+			continue
+		}
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		fields[name] = field
+	}
+	return json.Marshal(fields)
+}
+
+// accountPatchJSON represents request payload of the account update.
+type accountPatchJSON struct {
+	Data accountPatch `json:"data"`
+}
+
+// accountPatch represents an account modification.
+type accountPatch struct {
+	Attributes interface{} `json:"attributes"`
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	Version    int64       `json:"version"`
+}
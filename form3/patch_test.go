@@ -0,0 +1,92 @@
+package form3_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/lmikolajczak/go-form3/form3"
+	"net/http"
+	"testing"
+)
+
+func TestAccountAttributesPatch_MarshalJSON(t *testing.T) {
+	testcases := []struct {
+		name  string
+		patch form3.AccountAttributesPatch
+		want  string
+	}{
+		{
+			name: "unchanged",
+			want: `{}`,
+		},
+		{
+			name: "set to value",
+			patch: form3.AccountAttributesPatch{
+				Name:   form3.Set([]string{"New Name"}),
+				Status: form3.Set("confirmed"),
+			},
+			want: `{"name":["New Name"],"status":"confirmed"}`,
+		},
+		{
+			name: "cleared to null",
+			patch: form3.AccountAttributesPatch{
+				SecondaryIdentification: form3.Null[string](),
+				Switched:                form3.Set(false),
+			},
+			want: `{"secondary_identification":null,"switched":false}`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.Marshal(tc.patch)
+			if err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("json = %s; want: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNullable_OmitZero(t *testing.T) {
+	patch := struct {
+		Name   form3.Nullable[string] `json:"name,omitzero"`
+		Status form3.Nullable[string] `json:"status,omitzero"`
+		Reason form3.Nullable[string] `json:"reason,omitzero"`
+	}{
+		Status: form3.Set("confirmed"),
+		Reason: form3.Null[string](),
+	}
+
+	got, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if want := `{"status":"confirmed","reason":null}`; string(got) != want {
+		t.Errorf("json = %s; want: %s", got, want)
+	}
+}
+
+func TestAccountsService_Update(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t)
+	defer teardown()
+
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPatch)
+		testBody(t, r, `{"data":{"attributes":{"bic":null,"name":["New Name"]},"id":"ad27e265","type":"accounts","version":2}}`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"ad27e265","version":3,"attributes":{"name":["New Name"]}}}`))
+	})
+
+	account, err := f3.Accounts.Update(context.Background(), "ad27e265", 2, &form3.AccountAttributesPatch{
+		Bic:  form3.Null[string](),
+		Name: form3.Set([]string{"New Name"}),
+	})
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if account.Version == nil || *account.Version != 3 {
+		t.Errorf("version = %v; want: 3", account.Version)
+	}
+}
@@ -0,0 +1,165 @@
+package form3
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate limit headers of the API responses. RateLimitResetHeader holds either the
+// number of seconds until the limit resets or the Unix time it resets at.
+const (
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// WithRateLimiter makes the client wait for the limiter before every attempt of every
+// request. The limiter may be shared by several clients.
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithOperationRateLimiter makes the client wait for the limiter before every attempt
+// of the requests performing the given operation, in addition to the limiter set
+// with WithRateLimiter.
+func WithOperationRateLimiter(operation Operation, limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		if c.operationRateLimiters == nil {
+			c.operationRateLimiters = map[Operation]*RateLimiter{}
+		}
+		c.operationRateLimiters[operation] = limiter
+	}
+}
+
+// RateLimiter is a token bucket rate limiter safe for concurrent use. It allows bursts
+// of up to burst requests and refills at rate requests per second. The rate is lowered
+// temporarily according to the rate limit headers of the responses, so the remaining
+// requests are spread until the limit resets.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	// last is the time the tokens were last refilled at. It is in the future while
+	// the limit of the API is exhausted.
+	last time.Time
+	// adaptedRate is the rate derived from the rate limit headers used until adaptedUntil.
+	adaptedRate  float64
+	adaptedUntil time.Time
+	now          func() time.Time
+}
+
+// NewRateLimiter returns a new rate limiter allowing rate requests per second with
+// bursts of up to burst requests. A rate of zero or less does not limit requests,
+// unless the API reports that its limit is exhausted.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), now: time.Now}
+}
+
+// Wait blocks until a request is allowed or the context is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	l.refill(now)
+	l.tokens--
+	var delay time.Duration
+	if now.Before(l.last) {
+		delay = l.last.Sub(now)
+	}
+	if rate := l.currentRate(now); l.tokens < 0 && rate > 0 {
+		delay += time.Duration(-l.tokens / rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, delay); err != nil {
+		// Give back the token reserved for the request that is not going to be sent.
+		l.mu.Lock()
+		l.tokens = math.Min(l.tokens+1, l.burst)
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (l *RateLimiter) refill(now time.Time) {
+	if now.Before(l.last) {
+		return
+	}
+	if rate := l.currentRate(now); rate > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*rate)
+	} else {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// currentRate returns the rate adapted to the rate limit headers, if any.
+func (l *RateLimiter) currentRate(now time.Time) float64 {
+	if now.Before(l.adaptedUntil) && (l.rate <= 0 || l.adaptedRate < l.rate) {
+		return l.adaptedRate
+	}
+	return l.rate
+}
+
+// observe adapts the limiter to the rate limit headers of the response.
+func (l *RateLimiter) observe(response *http.Response) {
+	remaining, err := strconv.Atoi(response.Header.Get(RateLimitRemainingHeader))
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	reset, ok := parseRateLimitReset(response.Header.Get(RateLimitResetHeader), now)
+	if !ok {
+		return
+	}
+
+	l.refill(now)
+	if remaining <= 0 {
+		// Hold off all requests until the limit resets.
+		l.tokens, l.last = math.Min(l.tokens, 0), reset
+		return
+	}
+	l.tokens = math.Min(l.tokens, float64(remaining))
+	l.adaptedRate, l.adaptedUntil = float64(remaining)/reset.Sub(now).Seconds(), reset
+}
+
+// parseRateLimitReset returns the time the rate limit resets at.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+	var reset time.Time
+	if seconds > 1e9 {
+		reset = time.Unix(0, int64(seconds*float64(time.Second)))
+	} else {
+		reset = now.Add(time.Duration(seconds * float64(time.Second)))
+	}
+	return reset, reset.After(now)
+}
+
+// rateLimiters returns the limiters applying to the operation.
+func (c *Client) rateLimiters(operation Operation) []*RateLimiter {
+	var limiters []*RateLimiter
+	if limiter, ok := c.operationRateLimiters[operation]; ok {
+		limiters = append(limiters, limiter)
+	}
+	if c.rateLimiter != nil {
+		limiters = append(limiters, c.rateLimiter)
+	}
+	return limiters
+}
@@ -0,0 +1,97 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"github.com/lmikolajczak/go-form3/form3"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	limiter := form3.NewRateLimiter(20, 2)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("err = %v; want: nil", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Burst of 2 requests, then 1 request every 50ms.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("elapsed = %s; want: at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitCancelled(t *testing.T) {
+	limiter := form3.NewRateLimiter(0.1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v; want: %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWithOperationRateLimiter(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithOperationRateLimiter(form3.OperationFetchAccount, form3.NewRateLimiter(0.1, 1)))
+	defer teardown()
+
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"ad27e265"}}`))
+	})
+	mux.HandleFunc("/v1/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[]}`))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := f3.Accounts.Fetch(ctx, "ad27e265"); err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if _, err := f3.Accounts.Fetch(ctx, "ad27e265"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v; want: %v", err, context.DeadlineExceeded)
+	}
+	// Other operations are not limited.
+	if _, err := f3.Accounts.List(context.Background(), nil); err != nil {
+		t.Errorf("err = %v; want: nil", err)
+	}
+}
+
+func TestWithRateLimiter_Headers(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithRateLimiter(form3.NewRateLimiter(1000, 10)))
+	defer teardown()
+
+	var times []time.Time
+	mux.HandleFunc("/v1/organisation/accounts/ad27e265", func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		// The limit is exhausted for the next 200ms.
+		w.Header().Set(form3.RateLimitRemainingHeader, "0")
+		w.Header().Set(form3.RateLimitResetHeader, "0.2")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"ad27e265"}}`))
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := f3.Accounts.Fetch(context.Background(), "ad27e265"); err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+	}
+	if elapsed := times[1].Sub(times[0]); elapsed < 180*time.Millisecond {
+		t.Errorf("elapsed = %s; want: at least 200ms", elapsed)
+	}
+}
@@ -0,0 +1,204 @@
+package form3
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header that marks a non-idempotent request
+// (e.g. POST) as safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy describes if and how failed requests are retried by Client.Request.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// Values lower than 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles with every attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. Responses asking to retry after
+	// a longer delay with Retry-After are returned without retrying.
+	MaxDelay time.Duration
+	// Jitter randomises the delay between 0 and the computed backoff ("full jitter").
+	Jitter bool
+	// RetryableStatusCodes lists the HTTP status codes that are retried.
+	RetryableStatusCodes []int
+	// RetryableError reports whether a transport error is retried.
+	// DefaultRetryableError is used when nil.
+	RetryableError func(err error) bool
+}
+
+// DefaultRetryPolicy returns a retry policy with sensible defaults: up to 3 attempts,
+// exponential backoff with jitter between 100ms and 5s, retrying 429 and 5xx
+// gateway/availability errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      true,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy allows to set a retry policy used for every request.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// DefaultRetryableError reports whether err is a transient transport error, such as
+// a reset connection or a network timeout. Context cancellation, certificate errors
+// and other permanent errors, e.g. an unsupported protocol scheme, are never retried.
+func DefaultRetryableError(err error) bool {
+	// *url.Error returned by http.Client implements net.Error for any underlying error.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalidCertificate x509.CertificateInvalidError
+	var invalidHostname x509.HostnameError
+	if errors.As(err, &unknownAuthority) || errors.As(err, &invalidCertificate) || errors.As(err, &invalidHostname) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retry reports whether the request should be attempted again given the outcome
+// of the previous attempt.
+func (p RetryPolicy) retry(attempt int, request *http.Request, response *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts || !idempotent(request) || !rewindable(request) {
+		return false
+	}
+	if err != nil {
+		if p.RetryableError != nil {
+			return p.RetryableError(err)
+		}
+		return DefaultRetryableError(err)
+	}
+	if d, ok := retryAfter(response); ok && p.MaxDelay > 0 && d > p.MaxDelay {
+		return false
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if response.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before the given retry attempt. Retry-After sent
+// with 429 and 503 responses takes precedence over the computed backoff.
+func (p RetryPolicy) delay(attempt int, response *http.Response) time.Duration {
+	if d, ok := retryAfter(response); ok {
+		return d
+	}
+
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter && d > 0 {
+		d = time.Duration(rand.Int63n(int64(d) + 1))
+	}
+	return d
+}
+
+// retryAfter returns the delay requested with Retry-After by 429 and 503 responses.
+func retryAfter(response *http.Response) (time.Duration, bool) {
+	if response == nil {
+		return 0, false
+	}
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses the Retry-After header value given either in seconds
+// or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// idempotent reports whether the request can be safely sent more than once.
+func idempotent(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return request.Header.Get(IdempotencyKeyHeader) != ""
+	}
+}
+
+// rewindable reports whether the request body can be replayed.
+func rewindable(request *http.Request) bool {
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+// rewind returns a copy of the request with a fresh copy of its body.
+func rewind(request *http.Request) (*http.Request, error) {
+	clone := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// sleep pauses for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
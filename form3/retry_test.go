@@ -0,0 +1,152 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lmikolajczak/go-form3/form3"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() form3.RetryPolicy {
+	policy := form3.DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+	return policy
+}
+
+func TestClient_RequestRetry(t *testing.T) {
+	testcases := []struct {
+		name         string
+		method       string
+		headers      map[string]string
+		statuses     []int
+		wantAttempts int
+		wantStatus   int
+	}{
+		{
+			name:         "idempotent request succeeds after retries",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantAttempts: 3,
+		},
+		{
+			name:         "attempts are exhausted",
+			method:       http.MethodDelete,
+			statuses:     []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			wantAttempts: 3,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		{
+			name:         "non retryable status",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusNotFound},
+			wantAttempts: 1,
+			wantStatus:   http.StatusNotFound,
+		},
+		{
+			name:         "post without idempotency key",
+			method:       http.MethodPost,
+			statuses:     []int{http.StatusServiceUnavailable},
+			wantAttempts: 1,
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:         "post with idempotency key",
+			method:       http.MethodPost,
+			headers:      map[string]string{form3.IdempotencyKeyHeader: "key"},
+			statuses:     []int{http.StatusTooManyRequests, http.StatusCreated},
+			wantAttempts: 2,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f3, mux, teardown := form3.TestClientWithServer(t, form3.WithRetryPolicy(testRetryPolicy()))
+			defer teardown()
+
+			attempts := 0
+			mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if got, want := string(body), `{"example":"replayed"}`; got != want {
+					t.Errorf("attempt %d body: %s; want %s", attempts+1, got, want)
+				}
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(tc.statuses[attempts])
+				attempts++
+			})
+
+			request, err := f3.NewRequest(tc.method, "/test", map[string]string{"example": "replayed"})
+			if err != nil {
+				t.Fatalf("err = %v; want: nil", err)
+			}
+
+			err = f3.Request(nil, request, tc.headers)
+			if attempts != tc.wantAttempts {
+				t.Errorf("attempts = %d; want: %d", attempts, tc.wantAttempts)
+			}
+
+			var f3Error *form3.F3Error
+			switch {
+			case tc.wantStatus == 0 && err != nil:
+				t.Errorf("err = %v; want: nil", err)
+			case tc.wantStatus != 0 && !errors.As(err, &f3Error):
+				t.Errorf("err = %v; want: F3Error", err)
+			case tc.wantStatus != 0 && f3Error.StatusCode != tc.wantStatus:
+				t.Errorf("status = %d; want: %d", f3Error.StatusCode, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDefaultRetryableError(t *testing.T) {
+	urlError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://api.form3.tech", Err: err}
+	}
+	testcases := []struct {
+		err  error
+		want bool
+	}{
+		{err: urlError(io.EOF), want: true},
+		{err: urlError(fmt.Errorf("read: %w", syscall.ECONNRESET)), want: true},
+		{err: urlError(os.ErrDeadlineExceeded), want: true},
+		{err: urlError(errors.New("unsupported protocol scheme \"foo\"")), want: false},
+		{err: urlError(context.Canceled), want: false},
+		{err: errors.New("unknown"), want: false},
+	}
+
+	for _, tc := range testcases {
+		if got := form3.DefaultRetryableError(tc.err); got != tc.want {
+			t.Errorf("DefaultRetryableError(%v) = %t; want: %t", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestClient_RequestRetryAfterExceedsMaxDelay(t *testing.T) {
+	f3, mux, teardown := form3.TestClientWithServer(t, form3.WithRetryPolicy(testRetryPolicy()))
+	defer teardown()
+
+	attempts := 0
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	request, err := f3.NewRequest(http.MethodGet, "/test", nil)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	if err := f3.Request(nil, request, nil); !errors.Is(err, form3.ErrServer) {
+		t.Errorf("err = %v; want: %v", err, form3.ErrServer)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d; want: 1", attempts)
+	}
+}
@@ -0,0 +1,27 @@
+package form3
+
+import "net/http"
+
+// Signer signs requests before they are sent, e.g. *httpsig.Signer implementing
+// HTTP message signatures required by the Form3 REST API.
+type Signer interface {
+	Sign(request *http.Request) error
+}
+
+// WithSigner makes the client sign every request, including retried attempts, with
+// the given signer right before it is sent. The signature is set in the Authorization
+// header, so signing cannot be combined with WithTokenSource: every request of a
+// client configured with both fails without being sent.
+//
+// For example, to sign requests with a PEM encoded private key:
+//
+//	signer, err := httpsig.NewSignerFromPEM(keyID, privateKeyPEM)
+//	if err != nil {
+//		return err
+//	}
+//	f3 := form3.NewClient(baseURL, form3.WithSigner(signer))
+func WithSigner(signer Signer) ClientOption {
+	return func(c *Client) {
+		c.signer = signer
+	}
+}
@@ -7,6 +7,10 @@ import (
 	"testing"
 )
 
+// TestClient returns a client talking to the Form3 account API at FORM3_API_BASE_URL
+// or, if the variable is not set, at http://localhost:8080.
+//
+// Deprecated: Use form3test.TestClient instead, which falls back to a fake server.
 func TestClient(t *testing.T) (*Client, func()) {
 	t.Helper()
 	baseURL := os.Getenv("FORM3_API_BASE_URL")
@@ -16,11 +20,11 @@ func TestClient(t *testing.T) (*Client, func()) {
 	return NewClient(baseURL), func() {}
 }
 
-func TestClientWithServer(t *testing.T) (*Client, *http.ServeMux, func()) {
+func TestClientWithServer(t *testing.T, options ...ClientOption) (*Client, *http.ServeMux, func()) {
 	t.Helper()
 	mux := http.NewServeMux()
 	server := httptest.NewServer(mux)
-	c := NewClient(server.URL)
+	c := NewClient(server.URL, options...)
 	return c, mux, func() {
 		server.Close()
 	}
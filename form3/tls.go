@@ -0,0 +1,149 @@
+package form3
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// WithClientCertificate makes the client authenticate with the PEM encoded certificate
+// and private key when the server requests a client certificate (mutual TLS).
+//
+// Like the other TLS options, it configures the transport of the HTTP client, so the
+// HTTP client must be the default one or a *http.Client with a *http.Transport, which
+// is copied and not modified. Invalid TLS options are reported by every request.
+func WithClientCertificate(certPEM, keyPEM []byte) ClientOption {
+	return withTLS(func(config *tls.Config) error {
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+		return nil
+	})
+}
+
+// WithClientCertificateFiles makes the client authenticate with the PEM encoded
+// certificate and private key stored in the given files. The files are reloaded when
+// they are modified, so rotated certificates are used for new connections without
+// restarting the process. If reloading fails, the previous certificate is used.
+func WithClientCertificateFiles(certFile, keyFile string) ClientOption {
+	return withTLS(func(config *tls.Config) error {
+		reloader := &certificateReloader{certFile: certFile, keyFile: keyFile}
+		if _, err := reloader.certificate(); err != nil {
+			return err
+		}
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return reloader.certificate()
+		}
+		return nil
+	})
+}
+
+// WithRootCAs sets the certificate authorities used to verify the certificate of the
+// server instead of the system ones.
+func WithRootCAs(pool *x509.CertPool) ClientOption {
+	return withTLS(func(config *tls.Config) error {
+		config.RootCAs = pool
+		return nil
+	})
+}
+
+// WithMinTLSVersion sets the minimum TLS version accepted, e.g. tls.VersionTLS13.
+func WithMinTLSVersion(version uint16) ClientOption {
+	return withTLS(func(config *tls.Config) error {
+		config.MinVersion = version
+		return nil
+	})
+}
+
+// withTLS returns an option applying the given change to the TLS configuration of
+// the HTTP client.
+func withTLS(option func(*tls.Config) error) ClientOption {
+	return func(c *Client) {
+		c.tlsOptions = append(c.tlsOptions, option)
+	}
+}
+
+// configureTLS applies the TLS options to a copy of the HTTP client and its transport.
+func (c *Client) configureTLS() error {
+	httpClient, ok := c.httpClient.(*http.Client)
+	if !ok {
+		return fmt.Errorf("form3: TLS options require *http.Client, got %T", c.httpClient)
+	}
+
+	var transport *http.Transport
+	switch t := httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return fmt.Errorf("form3: TLS options require *http.Transport, got %T", t)
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = new(tls.Config)
+	}
+	for _, option := range c.tlsOptions {
+		if err := option(transport.TLSClientConfig); err != nil {
+			return fmt.Errorf("form3: %w", err)
+		}
+	}
+
+	configured := *httpClient
+	configured.Transport = transport
+	c.httpClient = &configured
+	return nil
+}
+
+// certificateReloader loads a certificate from files and reloads it when they change.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// certificate returns the current certificate, reloading it if the files were modified.
+func (r *certificateReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err == nil && r.cert != nil && !modTime.After(r.modTime) {
+		return r.cert, nil
+	}
+	if err == nil {
+		var certificate tls.Certificate
+		if certificate, err = tls.LoadX509KeyPair(r.certFile, r.keyFile); err == nil {
+			r.cert, r.modTime = &certificate, modTime
+			return r.cert, nil
+		}
+	}
+	if r.cert != nil {
+		// The files may be in the middle of rotation, keep the previous certificate.
+		return r.cert, nil
+	}
+	return nil, fmt.Errorf("client certificate: %w", err)
+}
+
+// latestModTime returns the latest modification time of the files.
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
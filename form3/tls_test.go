@@ -0,0 +1,148 @@
+package form3_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/lmikolajczak/go-form3/form3"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a new self-signed PEM encoded client certificate with the
+// given common name and its PEM encoded private key.
+func testCertificate(t *testing.T, commonName string) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("err = %v; want: nil", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+// testTLSServer starts a TLS server requiring client certificates signed by any of the
+// given certificates. It responds with the common name of the client certificate.
+func testTLSServer(t *testing.T, clientCerts ...[]byte) *httptest.Server {
+	t.Helper()
+
+	clientCAs := x509.NewCertPool()
+	for _, cert := range clientCerts {
+		clientCAs.AppendCertsFromPEM(cert)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testRootCAs returns a pool trusting the certificate of the server.
+func testRootCAs(server *httptest.Server) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return pool
+}
+
+// testPeer returns the common name of the client certificate seen by the server.
+func testPeer(t *testing.T, f3 *form3.Client) string {
+	t.Helper()
+
+	_, err := f3.Accounts.Fetch(context.Background(), "ad27e265")
+	var f3Error *form3.F3Error
+	if !errors.As(err, &f3Error) {
+		t.Fatalf("err = %v; want: http 404", err)
+	}
+	return f3Error.RequestID
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	certPEM, keyPEM := testCertificate(t, "client")
+	server := testTLSServer(t, certPEM)
+
+	f3 := form3.NewClient(server.URL, form3.WithClientCertificate(certPEM, keyPEM), form3.WithRootCAs(testRootCAs(server)), form3.WithMinTLSVersion(tls.VersionTLS12))
+	if got := testPeer(t, f3); got != "client" {
+		t.Errorf("peer = %s; want: client", got)
+	}
+
+	// Without the client certificate, the handshake fails.
+	f3 = form3.NewClient(server.URL, form3.WithRootCAs(testRootCAs(server)))
+	if _, err := f3.Accounts.Fetch(context.Background(), "ad27e265"); !errors.As(err, new(*form3.TransportError)) {
+		t.Errorf("err = %v; want: transport error", err)
+	}
+}
+
+func TestWithClientCertificate_Invalid(t *testing.T) {
+	f3 := form3.NewClient("https://localhost", form3.WithClientCertificate([]byte("cert"), []byte("key")))
+	if _, err := f3.Accounts.Fetch(context.Background(), "ad27e265"); err == nil {
+		t.Errorf("err = nil; want: client certificate error")
+	}
+
+	// Custom HTTP clients are copied, not modified.
+	form3.NewClient("https://localhost", form3.WithHTTPClient(http.DefaultClient), form3.WithMinTLSVersion(tls.VersionTLS13))
+	if got := http.DefaultClient.Transport; got != nil {
+		t.Errorf("transport = %v; want: default transport unchanged", got)
+	}
+}
+
+func TestWithClientCertificateFiles(t *testing.T) {
+	oldCert, oldKey := testCertificate(t, "old")
+	newCert, newKey := testCertificate(t, "new")
+	server := testTLSServer(t, oldCert, newCert)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writeFile := func(name string, data []byte, modTime time.Time) {
+		if err := os.WriteFile(name, data, 0o600); err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+		if err := os.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatalf("err = %v; want: nil", err)
+		}
+	}
+	writeFile(certFile, oldCert, time.Now().Add(-time.Minute))
+	writeFile(keyFile, oldKey, time.Now().Add(-time.Minute))
+
+	f3 := form3.NewClient(server.URL, form3.WithClientCertificateFiles(certFile, keyFile), form3.WithRootCAs(testRootCAs(server)))
+	if got := testPeer(t, f3); got != "old" {
+		t.Errorf("peer = %s; want: old", got)
+	}
+
+	// Rotated certificate is used for new connections.
+	writeFile(certFile, newCert, time.Now())
+	writeFile(keyFile, newKey, time.Now())
+	server.CloseClientConnections()
+	if got := testPeer(t, f3); got != "new" {
+		t.Errorf("peer = %s; want: new", got)
+	}
+}
@@ -0,0 +1,136 @@
+package form3
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rules of the field validation reported in FieldError.
+const (
+	RuleRequired     = "required"
+	RulePattern      = "pattern"
+	RuleEnum         = "enum"
+	RuleType         = "type"
+	RuleMinItems     = "min_items"
+	RuleMaxItems     = "max_items"
+	RuleMinLength    = "min_length"
+	RuleMaxLength    = "max_length"
+	RuleMinimum      = "minimum"
+	RuleMaximum      = "maximum"
+	RuleNotSupported = "not_supported"
+	RuleInvalid      = "invalid"
+)
+
+// validationFailureList prefixes every level of the validation failure messages
+// returned by the Form3 REST API.
+const validationFailureList = "validation failure list:"
+
+// FieldError represents a validation failure of a single field, e.g.
+// {Field: "account_number", Rule: "pattern", Detail: "^[A-Z0-9]{0,64}$"}.
+type FieldError struct {
+	// Field is the JSON name of the invalid field. Items of lists are referred to
+	// by their index, e.g. "name.0".
+	Field string
+	// Rule is the violated validation rule, one of the Rule* constants.
+	Rule string
+	// Detail is the parameter of the rule, e.g. the pattern, allowed values or limit.
+	Detail string
+}
+
+// Error returns a string representation of the FieldError in the format used by
+// the Form3 REST API.
+func (e FieldError) Error() string {
+	var message string
+	switch e.Rule {
+	case RuleRequired:
+		message = "is required"
+	case RulePattern:
+		message = fmt.Sprintf("should match '%s'", e.Detail)
+	case RuleEnum:
+		message = fmt.Sprintf("should be one of [%s]", e.Detail)
+	case RuleType:
+		message = fmt.Sprintf("must be of type %s", e.Detail)
+	case RuleMinItems:
+		message = fmt.Sprintf("should have at least %s items", e.Detail)
+	case RuleMaxItems:
+		message = fmt.Sprintf("should have at most %s items", e.Detail)
+	case RuleMinLength:
+		message = fmt.Sprintf("should be at least %s chars long", e.Detail)
+	case RuleMaxLength:
+		message = fmt.Sprintf("should be at most %s chars long", e.Detail)
+	case RuleMinimum:
+		message = fmt.Sprintf("should be greater than or equal to %s", e.Detail)
+	case RuleMaximum:
+		message = fmt.Sprintf("should be less than or equal to %s", e.Detail)
+	case RuleNotSupported:
+		message = fmt.Sprintf("is not supported in %s", e.Detail)
+	default:
+		message = e.Detail
+	}
+	if e.Field == "" {
+		return message
+	}
+	return fmt.Sprintf("%s in body %s", e.Field, message)
+}
+
+// fieldFailure matches a single validation failure, e.g.
+// "account_number in body should match '^[A-Z0-9]{0,64}$'".
+var fieldFailure = regexp.MustCompile(`^(\S+) in (?:body|query|path|header) (.+)$`)
+
+// Rules recognised in the validation failures.
+var ruleFailures = []struct {
+	rule    string
+	pattern *regexp.Regexp
+}{
+	{RuleRequired, regexp.MustCompile(`^is required$`)},
+	{RulePattern, regexp.MustCompile(`^should match '(.*)'$`)},
+	{RuleEnum, regexp.MustCompile(`^should be one of \[(.*)\]$`)},
+	{RuleType, regexp.MustCompile(`^must be of type (.+?)(?::.*)?$`)},
+	{RuleMinItems, regexp.MustCompile(`^should have at least (\d+) items$`)},
+	{RuleMaxItems, regexp.MustCompile(`^should have at most (\d+) items$`)},
+	{RuleMinLength, regexp.MustCompile(`^should be at least (\d+) chars long$`)},
+	{RuleMaxLength, regexp.MustCompile(`^should be at most (\d+) chars long$`)},
+	{RuleMinimum, regexp.MustCompile(`^should be greater than or equal to (.+)$`)},
+	{RuleMaximum, regexp.MustCompile(`^should be less than or equal to (.+)$`)},
+}
+
+// ParseValidationErrors parses the "validation failure list" message returned by the
+// Form3 REST API into field errors. Failures that do not refer to a field are
+// returned with an empty Field and RuleInvalid. It returns nil if the message is
+// not a validation failure list.
+func ParseValidationErrors(message string) []FieldError {
+	if !strings.HasPrefix(message, validationFailureList) {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == validationFailureList {
+			continue
+		}
+		fieldErrors = append(fieldErrors, parseFieldError(line))
+	}
+	return fieldErrors
+}
+
+// parseFieldError parses a single validation failure.
+func parseFieldError(failure string) FieldError {
+	match := fieldFailure.FindStringSubmatch(failure)
+	if match == nil {
+		return FieldError{Rule: RuleInvalid, Detail: failure}
+	}
+
+	field, description := match[1], match[2]
+	for _, r := range ruleFailures {
+		if m := r.pattern.FindStringSubmatch(description); m != nil {
+			detail := ""
+			if len(m) > 1 {
+				detail = m[1]
+			}
+			return FieldError{Field: field, Rule: r.rule, Detail: detail}
+		}
+	}
+	return FieldError{Field: field, Rule: RuleInvalid, Detail: description}
+}
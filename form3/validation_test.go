@@ -0,0 +1,85 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"github.com/go-test/deep"
+	"github.com/google/uuid"
+	"github.com/lmikolajczak/go-form3/form3"
+	"github.com/lmikolajczak/go-form3/form3/form3test"
+	"testing"
+)
+
+func TestParseValidationErrors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		message string
+		want    []form3.FieldError
+	}{
+		{
+			name:    "not a validation failure list",
+			message: "invalid version",
+		},
+		{
+			name:    "required",
+			message: "validation failure list:\nvalidation failure list:\nattributes in body is required",
+			want: []form3.FieldError{
+				{Field: "attributes", Rule: form3.RuleRequired},
+			},
+		},
+		{
+			name:    "multiple failures",
+			message: "validation failure list:\nvalidation failure list:\nvalidation failure list:\naccount_number in body should match '^[A-Z0-9]{0,64}$'\nname.0 in body should be at most 140 chars long\naccount_classification in body should be one of [Personal Business]\nname in body should have at most 4 items",
+			want: []form3.FieldError{
+				{Field: "account_number", Rule: form3.RulePattern, Detail: "^[A-Z0-9]{0,64}$"},
+				{Field: "name.0", Rule: form3.RuleMaxLength, Detail: "140"},
+				{Field: "account_classification", Rule: form3.RuleEnum, Detail: "Personal Business"},
+				{Field: "name", Rule: form3.RuleMaxItems, Detail: "4"},
+			},
+		},
+		{
+			name:    "type and unknown failures",
+			message: "validation failure list:\nid in body must be of type uuid: \"123\"\nsomething went wrong",
+			want: []form3.FieldError{
+				{Field: "id", Rule: form3.RuleType, Detail: "uuid"},
+				{Rule: form3.RuleInvalid, Detail: "something went wrong"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := deep.Equal(form3.ParseValidationErrors(tc.message), tc.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFieldError_Error(t *testing.T) {
+	err := form3.FieldError{Field: "account_number", Rule: form3.RulePattern, Detail: "^[A-Z0-9]{0,64}$"}
+	if got, want := err.Error(), "account_number in body should match '^[A-Z0-9]{0,64}$'"; got != want {
+		t.Errorf("error = %s; want: %s", got, want)
+	}
+}
+
+func TestF3Error_FieldErrors(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+
+	attributes := &form3.AccountAttributes{
+		AccountNumber: "%$#@!123654",
+		Country:       form3.String("NL"),
+		Name:          []string{"L. Mikolajczak"},
+	}
+	_, err := server.Client().Accounts.Create(context.Background(), uuid.NewString(), attributes)
+
+	var f3Error *form3.F3Error
+	if !errors.As(err, &f3Error) {
+		t.Fatalf("err = %v; want: F3Error", err)
+	}
+	want := []form3.FieldError{{Field: "account_number", Rule: form3.RulePattern, Detail: "^[A-Z0-9]{0,64}$"}}
+	if diff := deep.Equal(f3Error.FieldErrors, want); diff != nil {
+		t.Error(diff)
+	}
+}